@@ -0,0 +1,119 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.replica_host_status` of Amazon Aurora.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	aurora = "aurora"
+	// Queries.
+	auroraReplicaStatusTableQuery = `
+		SELECT COUNT(*)
+		  FROM information_schema.tables
+		  WHERE UPPER(table_schema) = 'INFORMATION_SCHEMA'
+		    AND UPPER(table_name) = 'REPLICA_HOST_STATUS'
+		`
+	auroraReplicaStatusQuery = `
+		SELECT server_id, session_id, replica_lag_in_milliseconds, cpu, is_current
+		  FROM information_schema.replica_host_status
+		`
+)
+
+// Metric descriptors.
+var (
+	auroraReplicaLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, aurora, "replica_lag_seconds"),
+		"Replication lag of the Aurora replica behind the writer.",
+		[]string{"server_id", "session_id"}, nil,
+	)
+	auroraReplicaCPUDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, aurora, "replica_cpu"),
+		"CPU usage of the Aurora replica in percent.",
+		[]string{"server_id", "session_id"}, nil,
+	)
+	auroraReplicaIsCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, aurora, "replica_is_current"),
+		"Whether the Aurora replica is the instance being scraped.",
+		[]string{"server_id", "session_id"}, nil,
+	)
+)
+
+// ScrapeAuroraReplicaStatus collects from `information_schema.replica_host_status`.
+type ScrapeAuroraReplicaStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeAuroraReplicaStatus) Name() string {
+	return "aurora.replica_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeAuroraReplicaStatus) Help() string {
+	return "Collect Aurora replica lag from information_schema.replica_host_status"
+}
+
+// Version of MySQL from which scraper is available. Aurora is only
+// available as MySQL 5.6 compatible and later, other servers are detected
+// in Scrape by the missing table.
+func (ScrapeAuroraReplicaStatus) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeAuroraReplicaStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	var tableCount uint64
+	if err := queryRowContext(ctx, db, auroraReplicaStatusTableQuery).Scan(&tableCount); err != nil {
+		return err
+	}
+	// Not running on Aurora.
+	if tableCount == 0 {
+		return nil
+	}
+
+	replicaStatusRows, err := queryContext(ctx, db, auroraReplicaStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer replicaStatusRows.Close()
+
+	var (
+		serverID, sessionID string
+		lagMs, cpu, current float64
+	)
+	for replicaStatusRows.Next() {
+		if err := replicaStatusRows.Scan(&serverID, &sessionID, &lagMs, &cpu, &current); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(auroraReplicaLagDesc, prometheus.GaugeValue, lagMs/1000, serverID, sessionID)
+		ch <- prometheus.MustNewConstMetric(auroraReplicaCPUDesc, prometheus.GaugeValue, cpu, serverID, sessionID)
+		ch <- prometheus.MustNewConstMetric(auroraReplicaIsCurrentDesc, prometheus.GaugeValue, current, serverID, sessionID)
+	}
+	return replicaStatusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeAuroraReplicaStatus{}
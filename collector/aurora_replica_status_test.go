@@ -0,0 +1,99 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeAuroraReplicaStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(auroraReplicaStatusTableQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	columns := []string{"server_id", "session_id", "replica_lag_in_milliseconds", "cpu", "is_current"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("db-writer", "MASTER_SESSION_ID", "0", "12.5", "1").
+		AddRow("db-reader-1", "b2f1a1c6-0b5e-4f0e-9dd6-7cfb7d2d4f31", "1500", "3.25", "0")
+	mock.ExpectQuery(sanitizeQuery(auroraReplicaStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeAuroraReplicaStatus{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	writer := labelMap{"server_id": "db-writer", "session_id": "MASTER_SESSION_ID"}
+	reader := labelMap{"server_id": "db-reader-1", "session_id": "b2f1a1c6-0b5e-4f0e-9dd6-7cfb7d2d4f31"}
+	metricExpected := []MetricResult{
+		{labels: writer, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: writer, value: 12.5, metricType: dto.MetricType_GAUGE},
+		{labels: writer, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: reader, value: 1.5, metricType: dto.MetricType_GAUGE},
+		{labels: reader, value: 3.25, metricType: dto.MetricType_GAUGE},
+		{labels: reader, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeAuroraReplicaStatusNotAurora(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(auroraReplicaStatusTableQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeAuroraReplicaStatus{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics outside of Aurora", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -50,8 +50,18 @@ var (
 		"The last binlog file number.",
 		[]string{}, nil,
 	)
+	binlogFileInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlog, "file_info"),
+		"Information about a registered binlog file, only available with the Encrypted column of MySQL 8.0.",
+		[]string{"file", "encrypted"}, nil,
+	)
 )
 
+// binlogFile holds the name and encryption of a registered binlog file.
+type binlogFile struct {
+	name, encrypted string
+}
+
 // ScrapeBinlogSize colects from `SHOW BINARY LOGS`.
 type ScrapeBinlogSize struct{}
 
@@ -73,7 +83,7 @@ func (ScrapeBinlogSize) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeBinlogSize) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	var logBin uint8
-	err := db.QueryRowContext(ctx, logbinQuery).Scan(&logBin)
+	err := queryRowContext(ctx, db, logbinQuery).Scan(&logBin)
 	if err != nil {
 		return err
 	}
@@ -82,7 +92,7 @@ func (ScrapeBinlogSize) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		return nil
 	}
 
-	masterLogRows, err := db.QueryContext(ctx, binlogQuery)
+	masterLogRows, err := queryContext(ctx, db, binlogQuery)
 	if err != nil {
 		return err
 	}
@@ -94,6 +104,7 @@ func (ScrapeBinlogSize) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		filename  string
 		filesize  uint64
 		encrypted string
+		files     []binlogFile
 	)
 	size = 0
 	count = 0
@@ -108,19 +119,29 @@ func (ScrapeBinlogSize) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		switch columnCount {
 		case 2:
 			if err := masterLogRows.Scan(&filename, &filesize); err != nil {
-				return nil
+				if skipScanError(ctx, err) {
+					continue
+				}
+				return err
 			}
 		case 3:
 			if err := masterLogRows.Scan(&filename, &filesize, &encrypted); err != nil {
-				return nil
+				if skipScanError(ctx, err) {
+					continue
+				}
+				return err
 			}
+			files = append(files, binlogFile{filename, strings.ToLower(encrypted)})
 		default:
-			return fmt.Errorf("invalid number of columns: %q", columnCount)
+			return fmt.Errorf("invalid number of columns: %d", columnCount)
 		}
 
 		size += filesize
 		count++
 	}
+	if err := masterLogRows.Err(); err != nil {
+		return err
+	}
 
 	ch <- prometheus.MustNewConstMetric(
 		binlogSizeDesc, prometheus.GaugeValue, float64(size),
@@ -129,10 +150,17 @@ func (ScrapeBinlogSize) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		binlogFilesDesc, prometheus.GaugeValue, float64(count),
 	)
 	// The last row contains the last binlog file number.
-	value, _ := strconv.ParseFloat(strings.Split(filename, ".")[1], 64)
-	ch <- prometheus.MustNewConstMetric(
-		binlogFileNumberDesc, prometheus.GaugeValue, value,
-	)
+	if i := strings.LastIndex(filename, "."); i >= 0 {
+		value, _ := strconv.ParseFloat(filename[i+1:], 64)
+		ch <- prometheus.MustNewConstMetric(
+			binlogFileNumberDesc, prometheus.GaugeValue, value,
+		)
+	}
+	for _, file := range files {
+		ch <- prometheus.MustNewConstMetric(
+			binlogFileInfoDesc, prometheus.GaugeValue, 1, file.name, file.encrypted,
+		)
+	}
 
 	return nil
 }
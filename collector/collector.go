@@ -15,11 +15,14 @@ package collector
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"regexp"
 	"strconv"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
 )
 
 const (
@@ -32,8 +35,15 @@ const (
 		OR Variable_Name='userstat_running'`
 )
 
+// maxExactFloat is the largest integer up to which float64 represents all
+// integers exactly.
+const maxExactFloat = 1 << 53
+
 var logRE = regexp.MustCompile(`.+\.(\d+)$`)
 
+// precisionLossWarned records the columns warned about by uint64ToFloat.
+var precisionLossWarned sync.Map
+
 func newDesc(subsystem, name, help string) *prometheus.Desc {
 	return prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, name),
@@ -41,6 +51,42 @@ func newDesc(subsystem, name, help string) *prometheus.Desc {
 	)
 }
 
+// newConstMetricFromSeconds sends a metric of seconds converted from a
+// value in picoseconds, the unit of performance_schema and sys timers.
+// Timers pass 2^53 picoseconds after two and a half hours, but float64 still
+// rounds them to a few nanoseconds over the whole uint64 range, so timers
+// need no precision check.
+func newConstMetricFromSeconds(ch chan<- prometheus.Metric, desc *prometheus.Desc, valueType prometheus.ValueType, value uint64, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(desc, valueType, float64(value)/picoSeconds, labels...)
+}
+
+// uint64ToFloat converts an integer counter to float64, warning once per
+// collector and column when the value is too large to be represented
+// exactly, as increments of the counter then get lost.
+func uint64ToFloat(collector, column string, value uint64) float64 {
+	if value > maxExactFloat {
+		warnPrecisionLoss(collector, column, value)
+	}
+	return float64(value)
+}
+
+// warnPrecisionLoss logs a precision loss of column once, it reports whether
+// it logged.
+func warnPrecisionLoss(collector, column string, value uint64) bool {
+	if _, warned := precisionLossWarned.LoadOrStore(collector+"/"+column, true); warned {
+		return false
+	}
+	log.Warnf("%s: %s value %d exceeds the float64 precision, the metric is rounded", collector, column, value)
+	return true
+}
+
+// userstatSupported reports whether the server may provide the userstat
+// statistics tables, which Oracle MySQL does not have.
+func userstatSupported(ctx context.Context) bool {
+	v, ok := serverVersionFromContext(ctx)
+	return !ok || v.Flavor != flavorMySQL
+}
+
 func parseStatus(data sql.RawBytes) (float64, bool) {
 	if bytes.Equal(data, []byte("Yes")) || bytes.Equal(data, []byte("ON")) {
 		return 1, true
@@ -14,10 +14,13 @@
 package collector
 
 import (
+	"math"
 	"strings"
+	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
 )
 
 type labelMap map[string]string
@@ -52,5 +55,54 @@ func sanitizeQuery(q string) string {
 	q = strings.Replace(q, "(", "\\(", -1)
 	q = strings.Replace(q, ")", "\\)", -1)
 	q = strings.Replace(q, "*", "\\*", -1)
+	q = strings.Replace(q, "$", "\\$", -1)
+	q = strings.Replace(q, "?", "\\?", -1)
+	q = strings.Replace(q, "+", "\\+", -1)
 	return q
 }
+
+func TestNewConstMetricFromSeconds(t *testing.T) {
+	desc := prometheus.NewDesc("test_seconds_total", "Test timer.", []string{"label"}, nil)
+
+	convey.Convey("Picoseconds are converted to seconds", t, func() {
+		for _, tt := range []struct {
+			value    uint64
+			expected float64
+		}{
+			{0, 0},
+			{1500000000000, 1.5},
+			{math.MaxUint64, float64(math.MaxUint64) / 1e12},
+		} {
+			ch := make(chan prometheus.Metric, 1)
+			newConstMetricFromSeconds(ch, desc, prometheus.CounterValue, tt.value, "value")
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, MetricResult{
+				labels: labelMap{"label": "value"}, value: tt.expected, metricType: dto.MetricType_COUNTER,
+			})
+		}
+	})
+	convey.Convey("The value type is kept", t, func() {
+		ch := make(chan prometheus.Metric, 1)
+		newConstMetricFromSeconds(ch, desc, prometheus.GaugeValue, 2500000000000, "value")
+		got := readMetric(<-ch)
+		convey.So(got, convey.ShouldResemble, MetricResult{
+			labels: labelMap{"label": "value"}, value: 2.5, metricType: dto.MetricType_GAUGE,
+		})
+	})
+}
+
+func TestUint64ToFloat(t *testing.T) {
+	convey.Convey("Precision loss is detected", t, func() {
+		convey.So(uint64ToFloat("test", "small", maxExactFloat), convey.ShouldEqual, float64(maxExactFloat))
+		_, warned := precisionLossWarned.Load("test/small")
+		convey.So(warned, convey.ShouldBeFalse)
+
+		convey.So(uint64ToFloat("test", "large", math.MaxUint64-1), convey.ShouldEqual, float64(math.MaxUint64))
+		_, warned = precisionLossWarned.Load("test/large")
+		convey.So(warned, convey.ShouldBeTrue)
+
+		// The warning is logged once per column.
+		convey.So(warnPrecisionLoss("test", "large", math.MaxUint64), convey.ShouldBeFalse)
+		convey.So(warnPrecisionLoss("test", "other", math.MaxUint64), convey.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// CredentialProvider returns the password of the MySQL user for passwords
+// that expire, such as the authentication tokens of cloud providers.
+type CredentialProvider interface {
+	// GetPassword returns the password to connect with. It is called
+	// before each new connection.
+	GetPassword(ctx context.Context) (string, error)
+}
+
+// credentialConnector opens connections to the dsn with the password of
+// the provider.
+type credentialConnector struct {
+	dsn      string
+	provider CredentialProvider
+	driver   driver.Driver
+}
+
+// Connect implements driver.Connector.
+func (c *credentialConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	cfg, err := mysqldriver.ParseDSN(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Passwd, err = c.provider.GetPassword(ctx); err != nil {
+		return nil, err
+	}
+	return c.driver.Open(cfg.FormatDSN())
+}
+
+// Driver implements driver.Connector.
+func (c *credentialConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// OpenDB opens the dsn, taking the password of each new connection from
+// provider if it is not nil.
+func OpenDB(dsn string, provider CredentialProvider) (*sql.DB, error) {
+	if provider == nil {
+		return sql.Open("mysql", dsn)
+	}
+	return openDBWithDriver(dsn, provider, mysqldriver.MySQLDriver{})
+}
+
+func openDBWithDriver(dsn string, provider CredentialProvider, d driver.Driver) (*sql.DB, error) {
+	// Fail early on invalid DSNs like sql.Open does.
+	if _, err := mysqldriver.ParseDSN(dsn); err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&credentialConnector{dsn: dsn, provider: provider, driver: d}), nil
+}
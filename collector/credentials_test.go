@@ -0,0 +1,85 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// tokenProvider returns a new token for each call.
+type tokenProvider struct {
+	calls int
+}
+
+func (p *tokenProvider) GetPassword(ctx context.Context) (string, error) {
+	p.calls++
+	return "token-" + strconv.Itoa(p.calls), nil
+}
+
+// recordingDriver records the DSNs connections are opened with.
+type recordingDriver struct {
+	dsns []string
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) {
+	d.dsns = append(d.dsns, dsn)
+	return stubConn{}, nil
+}
+
+type stubConn struct{}
+
+func (stubConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (stubConn) Close() error                              { return nil }
+func (stubConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not implemented") }
+
+func TestCredentialProvider(t *testing.T) {
+	provider := &tokenProvider{}
+	d := &recordingDriver{}
+	db, err := openDBWithDriver("exporter@tcp(db.example.com:3306)/?tls=true", provider, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// Do not reuse connections, so that each ping opens a new one.
+	db.SetMaxIdleConns(0)
+
+	for i := 0; i < 2; i++ {
+		if err := db.PingContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	convey.Convey("New connections use the current token", t, func() {
+		convey.So(d.dsns, convey.ShouldHaveLength, 2)
+		for i, dsn := range d.dsns {
+			cfg, err := mysqldriver.ParseDSN(dsn)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(cfg.User, convey.ShouldEqual, "exporter")
+			convey.So(cfg.Addr, convey.ShouldEqual, "db.example.com:3306")
+			convey.So(cfg.Passwd, convey.ShouldEqual, "token-"+strconv.Itoa(i+1))
+		}
+	})
+
+	convey.Convey("Invalid DSNs are rejected", t, func() {
+		_, err := openDBWithDriver("exporter@tcp(db.example.com:3306", provider, d)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the BUFFER POOL AND MEMORY section of `SHOW ENGINE INNODB STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric descriptors.
+var (
+	engineInnodbBufferPoolHitRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "buffer_pool_hit_rate"),
+		"Ratio of page gets served from the buffer pool since the last InnoDB monitor printout.",
+		nil, nil,
+	)
+	engineInnodbBufferPoolPagesRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "buffer_pool_pages_per_second"),
+		"Pages read, created and written per second, averaged since the last InnoDB monitor printout.",
+		[]string{"operation"}, nil,
+	)
+	engineInnodbBufferPoolLRULengthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "buffer_pool_lru_length"),
+		"Number of pages in the buffer pool LRU list.",
+		nil, nil,
+	)
+)
+
+// Regexp for parsing the buffer pool section.
+var (
+	// MySQL 8.0 prints "No buffer pool page gets since the last printout"
+	// instead of the hit rate when the buffer pool was idle.
+	innodbBufferPoolHitRateRE = regexp.MustCompile(`(?m)^Buffer pool hit rate (\d+) / (\d+)`)
+	innodbBufferPoolRatesRE   = regexp.MustCompile(`(?m)^([\d.]+) reads/s, ([\d.]+) creates/s, ([\d.]+) writes/s`)
+	innodbBufferPoolLRURE     = regexp.MustCompile(`(?m)^LRU len: (\d+)`)
+)
+
+// innodbBufferPool describes the BUFFER POOL AND MEMORY section.
+type innodbBufferPool struct {
+	// HitRate is only set when there were page gets since the last printout.
+	HitRate          float64
+	HasHitRate       bool
+	ReadsPerSecond   float64
+	CreatesPerSecond float64
+	WritesPerSecond  float64
+	LRULength        float64
+}
+
+// parseInnodbBufferPool extracts the buffer pool statistics from the output
+// of SHOW ENGINE INNODB STATUS. It returns false if the section is missing.
+func parseInnodbBufferPool(status string) (innodbBufferPool, bool) {
+	var pool innodbBufferPool
+
+	section, ok := innodbStatusSection(status, "BUFFER POOL AND MEMORY")
+	if !ok {
+		return pool, false
+	}
+	if match := innodbBufferPoolHitRateRE.FindStringSubmatch(section); match != nil {
+		hits, _ := strconv.ParseFloat(match[1], 64)
+		gets, _ := strconv.ParseFloat(match[2], 64)
+		if gets > 0 {
+			pool.HitRate = hits / gets
+			pool.HasHitRate = true
+		}
+	}
+	if match := innodbBufferPoolRatesRE.FindStringSubmatch(section); match != nil {
+		pool.ReadsPerSecond, _ = strconv.ParseFloat(match[1], 64)
+		pool.CreatesPerSecond, _ = strconv.ParseFloat(match[2], 64)
+		pool.WritesPerSecond, _ = strconv.ParseFloat(match[3], 64)
+	}
+	if match := innodbBufferPoolLRURE.FindStringSubmatch(section); match != nil {
+		pool.LRULength, _ = strconv.ParseFloat(match[1], 64)
+	}
+	return pool, true
+}
+
+// ScrapeInnodbStatusBufferPool scrapes the buffer pool statistics from `SHOW ENGINE INNODB STATUS`.
+type ScrapeInnodbStatusBufferPool struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbStatusBufferPool) Name() string {
+	return "engine_innodb_buffer_pool"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbStatusBufferPool) Help() string {
+	return "Collect the buffer pool hit rate and page rates from SHOW ENGINE INNODB STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbStatusBufferPool) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbStatusBufferPool) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := queryContext(ctx, db, engineInnodbStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var typeCol, nameCol, statusCol string
+	if rows.Next() {
+		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
+			return err
+		}
+	}
+
+	pool, ok := parseInnodbBufferPool(statusCol)
+	if !ok {
+		return nil
+	}
+	if pool.HasHitRate {
+		ch <- prometheus.MustNewConstMetric(engineInnodbBufferPoolHitRateDesc, prometheus.GaugeValue, pool.HitRate)
+	}
+	ch <- prometheus.MustNewConstMetric(engineInnodbBufferPoolPagesRateDesc, prometheus.GaugeValue, pool.ReadsPerSecond, "read")
+	ch <- prometheus.MustNewConstMetric(engineInnodbBufferPoolPagesRateDesc, prometheus.GaugeValue, pool.CreatesPerSecond, "created")
+	ch <- prometheus.MustNewConstMetric(engineInnodbBufferPoolPagesRateDesc, prometheus.GaugeValue, pool.WritesPerSecond, "written")
+	ch <- prometheus.MustNewConstMetric(engineInnodbBufferPoolLRULengthDesc, prometheus.GaugeValue, pool.LRULength)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbStatusBufferPool{}
@@ -0,0 +1,152 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const innodbBufferPoolSampleMySQL57 = `
+=====================================
+2019-07-10 10:25:01 0x7f1b2c1d0700 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 12 seconds
+----------------------
+BUFFER POOL AND MEMORY
+----------------------
+Total large memory allocated 137428992
+Dictionary memory allocated 145374
+Buffer pool size   8191
+Free buffers       6469
+Database pages     1717
+Old database pages 613
+Modified db pages  24
+Pending reads      0
+Pending writes: LRU 0, flush list 0, single page 0
+Pages made young 12, not young 0
+0.00 youngs/s, 0.00 non-youngs/s
+Pages read 1205, created 512, written 3310
+2.50 reads/s, 0.75 creates/s, 10.25 writes/s
+Buffer pool hit rate 998 / 1000, young-making rate 0 / 1000 not 0 / 1000
+Pages read ahead 0.00/s, evicted without access 0.00/s, Random read ahead 0.00/s
+LRU len: 1717, unzip_LRU len: 0
+I/O sum[0]:cur[0], unzip sum[0]:cur[0]
+--------------
+ROW OPERATIONS
+--------------
+0 queries inside InnoDB, 0 queries in queue
+`
+
+const innodbBufferPoolSampleMySQL80 = `
+=====================================
+2023-05-02 08:01:44 139770329556736 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 20 seconds
+----------------------
+BUFFER POOL AND MEMORY
+----------------------
+Total large memory allocated 0
+Dictionary memory allocated 520717
+Buffer pool size   8192
+Free buffers       6968
+Database pages     1217
+Old database pages 469
+Modified db pages  0
+Pending reads      0
+Pending writes: LRU 0, flush list 0, single page 0
+Pages made young 0, not young 0
+0.00 youngs/s, 0.00 non-youngs/s
+Pages read 1075, created 142, written 671
+0.00 reads/s, 0.00 creates/s, 0.00 writes/s
+No buffer pool page gets since the last printout
+Pages read ahead 0.00/s, evicted without access 0.00/s, Random read ahead 0.00/s
+LRU len: 1217, unzip_LRU len: 0
+I/O sum[0]:cur[0], unzip sum[0]:cur[0]
+--------------
+ROW OPERATIONS
+--------------
+0 queries inside InnoDB, 0 queries in queue
+`
+
+func TestParseInnodbBufferPool(t *testing.T) {
+	convey.Convey("Parse buffer pool and memory", t, func() {
+		convey.Convey("MySQL 5.7", func() {
+			pool, ok := parseInnodbBufferPool(innodbBufferPoolSampleMySQL57)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(pool, convey.ShouldResemble, innodbBufferPool{
+				HitRate:          0.998,
+				HasHitRate:       true,
+				ReadsPerSecond:   2.5,
+				CreatesPerSecond: 0.75,
+				WritesPerSecond:  10.25,
+				LRULength:        1717,
+			})
+		})
+		convey.Convey("MySQL 8.0 without page gets", func() {
+			pool, ok := parseInnodbBufferPool(innodbBufferPoolSampleMySQL80)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(pool, convey.ShouldResemble, innodbBufferPool{LRULength: 1217})
+		})
+		convey.Convey("No buffer pool section", func() {
+			_, ok := parseInnodbBufferPool(innodbDeadlockSampleNone)
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+	})
+}
+
+func TestScrapeInnodbStatusBufferPool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Type", "Name", "Status"}
+	mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("InnoDB", "", innodbBufferPoolSampleMySQL57))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbStatusBufferPool{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricsExpected := []MetricResult{
+		{labels: labelMap{}, value: 0.998, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"operation": "read"}, value: 2.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"operation": "created"}, value: 0.75, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"operation": "written"}, value: 10.25, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1717, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricsExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
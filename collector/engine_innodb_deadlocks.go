@@ -0,0 +1,189 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the LATEST DETECTED DEADLOCK section of `SHOW ENGINE INNODB STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric descriptors.
+var (
+	engineInnodbLatestDeadlockDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "latest_deadlock_timestamp_seconds"),
+		"Time of the latest deadlock detected by InnoDB.",
+		nil, nil,
+	)
+	engineInnodbDeadlocksObservedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "deadlocks_observed_total"),
+		"Number of distinct latest detected deadlocks observed by the exporter.",
+		nil, nil,
+	)
+)
+
+// Regexp for parsing the deadlock section.
+var (
+	// Section header lines are surrounded by lines of dashes.
+	innodbSectionRE = regexp.MustCompile(`(?m)^-+\n([A-Z][A-Z /]+)\n-+$`)
+	// MySQL 5.6+ and MariaDB 10 print "2019-07-10 10:20:30 0x7f1b2c1d0700",
+	// older servers print "190710 10:20:30".
+	innodbDeadlockTimeRE = regexp.MustCompile(`(?m)^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}|\d{6} {1,2}\d{1,2}:\d{2}:\d{2})`)
+	innodbDeadlockTrxRE  = regexp.MustCompile(`(?m)^\*\*\* \((\d+)\) TRANSACTION:`)
+)
+
+// innodbDeadlock describes the LATEST DETECTED DEADLOCK section.
+type innodbDeadlock struct {
+	Time         time.Time
+	Transactions int
+}
+
+// InnoDB prints timestamps in the system time zone of the server.
+const serverTimeZoneOffsetQuery = `SELECT TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), CONVERT_TZ(UTC_TIMESTAMP(), '+00:00', 'SYSTEM'))`
+
+// innodbDeadlockSeen is the latest deadlock seen on a server and the number
+// of distinct deadlocks counted.
+type innodbDeadlockSeen struct {
+	last  time.Time
+	count float64
+}
+
+// innodbDeadlockState remembers the latest deadlock seen per DSN, so that
+// every distinct deadlock is only counted once.
+var innodbDeadlockState = struct {
+	sync.Mutex
+	targets map[string]innodbDeadlockSeen
+}{targets: map[string]innodbDeadlockSeen{}}
+
+// serverTimeZone returns the system time zone of the server as a fixed
+// offset, or UTC if it cannot be determined.
+func serverTimeZone(ctx context.Context, db *sql.DB) *time.Location {
+	var offset int
+	if err := queryRowContext(ctx, db, serverTimeZoneOffsetQuery).Scan(&offset); err != nil {
+		return time.UTC
+	}
+	return time.FixedZone("", offset)
+}
+
+// parseInnodbDeadlock extracts the latest deadlock from the output of
+// SHOW ENGINE INNODB STATUS, whose timestamps are in loc. It returns false
+// if no deadlock was reported.
+func parseInnodbDeadlock(status string, loc *time.Location) (innodbDeadlock, bool) {
+	var deadlock innodbDeadlock
+
+	section, ok := innodbStatusSection(status, "LATEST DETECTED DEADLOCK")
+	if !ok {
+		return deadlock, false
+	}
+	match := innodbDeadlockTimeRE.FindStringSubmatch(section)
+	if match == nil {
+		return deadlock, false
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "060102 15:04:05", "060102  15:04:05"} {
+		if t, err := time.ParseInLocation(layout, match[1], loc); err == nil {
+			deadlock.Time = t
+			break
+		}
+	}
+	if deadlock.Time.IsZero() {
+		return deadlock, false
+	}
+	deadlock.Transactions = len(innodbDeadlockTrxRE.FindAllString(section, -1))
+	return deadlock, true
+}
+
+// innodbStatusSection returns the body of the named section of SHOW ENGINE INNODB STATUS.
+func innodbStatusSection(status, name string) (string, bool) {
+	status = strings.Replace(status, "\r\n", "\n", -1)
+	headers := innodbSectionRE.FindAllStringSubmatchIndex(status, -1)
+	for i, header := range headers {
+		if status[header[2]:header[3]] != name {
+			continue
+		}
+		end := len(status)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		return status[header[1]:end], true
+	}
+	return "", false
+}
+
+// ScrapeEngineInnodbDeadlocks scrapes the latest deadlock from `SHOW ENGINE INNODB STATUS`.
+type ScrapeEngineInnodbDeadlocks struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeEngineInnodbDeadlocks) Name() string {
+	return "engine_innodb_deadlocks"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeEngineInnodbDeadlocks) Help() string {
+	return "Collect the latest detected deadlock from SHOW ENGINE INNODB STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeEngineInnodbDeadlocks) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeEngineInnodbDeadlocks) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	loc := serverTimeZone(ctx, db)
+	rows, err := queryContext(ctx, db, engineInnodbStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var typeCol, nameCol, statusCol string
+	if rows.Next() {
+		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
+			return err
+		}
+	}
+
+	deadlock, ok := parseInnodbDeadlock(statusCol, loc)
+
+	target := targetFromContext(ctx)
+	innodbDeadlockState.Lock()
+	seen := innodbDeadlockState.targets[target]
+	if ok && !deadlock.Time.Equal(seen.last) {
+		seen.last = deadlock.Time
+		seen.count++
+		innodbDeadlockState.targets[target] = seen
+	}
+	count := seen.count
+	innodbDeadlockState.Unlock()
+
+	if ok {
+		ch <- prometheus.MustNewConstMetric(
+			engineInnodbLatestDeadlockDesc, prometheus.GaugeValue, float64(deadlock.Time.Unix()),
+		)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		engineInnodbDeadlocksObservedDesc, prometheus.CounterValue, count,
+	)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeEngineInnodbDeadlocks{}
@@ -0,0 +1,230 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const innodbDeadlockSampleMySQL57 = `
+=====================================
+2019-07-10 10:25:01 0x7f1b2c1d0700 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 30 seconds
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2019-07-10 10:20:30 0x7f1b2c1d0700
+*** (1) TRANSACTION:
+TRANSACTION 1863, ACTIVE 10 sec starting index read
+mysql tables in use 1, locked 1
+LOCK WAIT 3 lock struct(s), heap size 1136, 2 row lock(s)
+MySQL thread id 5, OS thread handle 139754469422848, query id 61 localhost root updating
+UPDATE t SET v = 1 WHERE id = 2
+*** (1) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 24 page no 3 n bits 72 index PRIMARY of table ` + "`test`.`t`" + ` trx id 1863 lock_mode X locks rec but not gap waiting
+*** (2) TRANSACTION:
+TRANSACTION 1864, ACTIVE 7 sec starting index read
+mysql tables in use 1, locked 1
+3 lock struct(s), heap size 1136, 2 row lock(s)
+MySQL thread id 6, OS thread handle 139754469156608, query id 62 localhost root updating
+UPDATE t SET v = 2 WHERE id = 1
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 1870
+`
+
+const innodbDeadlockSampleMySQL80 = `
+=====================================
+2023-05-02 08:00:00 140234567890688 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2023-05-02 07:59:12 140234567890688
+*** (1) TRANSACTION:
+TRANSACTION 2001, ACTIVE 3 sec starting index read
+*** (2) TRANSACTION:
+TRANSACTION 2002, ACTIVE 2 sec starting index read
+*** WE ROLL BACK TRANSACTION (1)
+------------
+TRANSACTIONS
+------------
+`
+
+const innodbDeadlockSampleMySQL55 = `
+=====================================
+140327 14:40:00 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+140327 14:35:34
+*** (1) TRANSACTION:
+TRANSACTION 1A2B, ACTIVE 1 sec starting index read
+*** (2) TRANSACTION:
+TRANSACTION 1A2C, ACTIVE 1 sec starting index read
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+`
+
+const innodbDeadlockSampleNone = `
+=====================================
+2019-07-10 10:25:01 0x7f1b2c1d0700 INNODB MONITOR OUTPUT
+=====================================
+------------
+TRANSACTIONS
+------------
+Trx id counter 1870
+`
+
+func TestParseInnodbDeadlock(t *testing.T) {
+	convey.Convey("Parse latest detected deadlock", t, func() {
+		convey.Convey("MySQL 5.7", func() {
+			deadlock, ok := parseInnodbDeadlock(innodbDeadlockSampleMySQL57, time.UTC)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(deadlock.Time, convey.ShouldEqual, time.Date(2019, 7, 10, 10, 20, 30, 0, time.UTC))
+			convey.So(deadlock.Transactions, convey.ShouldEqual, 2)
+		})
+		convey.Convey("MySQL 8.0", func() {
+			deadlock, ok := parseInnodbDeadlock(innodbDeadlockSampleMySQL80, time.UTC)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(deadlock.Time, convey.ShouldEqual, time.Date(2023, 5, 2, 7, 59, 12, 0, time.UTC))
+			convey.So(deadlock.Transactions, convey.ShouldEqual, 2)
+		})
+		convey.Convey("MySQL 5.5 and old MariaDB", func() {
+			deadlock, ok := parseInnodbDeadlock(innodbDeadlockSampleMySQL55, time.UTC)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(deadlock.Time, convey.ShouldEqual, time.Date(2014, 3, 27, 14, 35, 34, 0, time.UTC))
+		})
+		convey.Convey("Server time zone", func() {
+			loc := time.FixedZone("", 2*60*60)
+			deadlock, ok := parseInnodbDeadlock(innodbDeadlockSampleMySQL57, loc)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(deadlock.Time.Unix(), convey.ShouldEqual, time.Date(2019, 7, 10, 8, 20, 30, 0, time.UTC).Unix())
+		})
+		convey.Convey("No deadlock", func() {
+			_, ok := parseInnodbDeadlock(innodbDeadlockSampleNone, time.UTC)
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+	})
+}
+
+func TestScrapeEngineInnodbDeadlocks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	innodbDeadlockState.Lock()
+	innodbDeadlockState.targets = map[string]innodbDeadlockSeen{}
+	innodbDeadlockState.Unlock()
+
+	columns := []string{"Type", "Name", "Status"}
+	// The same deadlock reported twice must only be counted once.
+	for _, sample := range []string{innodbDeadlockSampleMySQL57, innodbDeadlockSampleMySQL57, innodbDeadlockSampleMySQL80} {
+		mock.ExpectQuery(regexp.QuoteMeta(serverTimeZoneOffsetQuery)).WillReturnRows(sqlmock.NewRows([]string{"offset"}).AddRow(0))
+		mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("InnoDB", "", sample))
+	}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		for i := 0; i < 3; i++ {
+			if err = (ScrapeEngineInnodbDeadlocks{}).Scrape(context.Background(), db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+		}
+		close(ch)
+	}()
+
+	metricsExpected := []MetricResult{
+		{labels: labelMap{}, value: 1562754030, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 1562754030, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 1683014352, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricsExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeEngineInnodbDeadlocksPerTarget(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	innodbDeadlockState.Lock()
+	innodbDeadlockState.targets = map[string]innodbDeadlockSeen{}
+	innodbDeadlockState.Unlock()
+
+	columns := []string{"Type", "Name", "Status"}
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta(serverTimeZoneOffsetQuery)).WillReturnRows(sqlmock.NewRows([]string{"offset"}).AddRow(0))
+		mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("InnoDB", "", innodbDeadlockSampleMySQL57))
+	}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		// The same deadlock on another server is counted separately.
+		for _, target := range []string{"tcp(db1:3306)/", "tcp(db2:3306)/"} {
+			if err = (ScrapeEngineInnodbDeadlocks{}).Scrape(withTarget(context.Background(), target), db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+		}
+		close(ch)
+	}()
+
+	metricsExpected := []MetricResult{
+		{labels: labelMap{}, value: 1562754030, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 1562754030, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricsExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the LOG section of `SHOW ENGINE INNODB STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric descriptors.
+var (
+	engineInnodbLogSequenceNumberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "log_sequence_number"),
+		"The current log sequence number of the redo log.",
+		nil, nil,
+	)
+	engineInnodbLogLastCheckpointDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "log_last_checkpoint_lsn"),
+		"The log sequence number of the last checkpoint.",
+		nil, nil,
+	)
+	engineInnodbLogCheckpointAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "log_checkpoint_age_bytes"),
+		"The amount of redo log written since the last checkpoint.",
+		nil, nil,
+	)
+	engineInnodbLogPendingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodb, "log_pending_writes"),
+		"The number of pending redo log flushes and checkpoint writes.",
+		[]string{"type"}, nil,
+	)
+)
+
+// Regexp for parsing the log section. LSNs are aligned with spaces, more of
+// them in MySQL 8.0, and MySQL 8.0 no longer prints the pending writes.
+var (
+	innodbLogSequenceNumberRE = regexp.MustCompile(`(?m)^Log sequence number\s+(\d+)`)
+	innodbLogLastCheckpointRE = regexp.MustCompile(`(?m)^Last checkpoint at\s+(\d+)`)
+	innodbLogPendingRE        = regexp.MustCompile(`(?m)^(\d+) pending log (?:flushes|writes), (\d+) pending chkp writes`)
+)
+
+// innodbRedoLog describes the LOG section.
+type innodbRedoLog struct {
+	SequenceNumber float64
+	LastCheckpoint float64
+	// PendingFlushes and PendingCheckpointWrites are only set when the
+	// server prints them.
+	PendingFlushes          float64
+	PendingCheckpointWrites float64
+	HasPending              bool
+}
+
+// CheckpointAge returns the amount of redo log written since the last
+// checkpoint, which InnoDB has to flush before it can reuse the log.
+func (l innodbRedoLog) CheckpointAge() float64 {
+	return l.SequenceNumber - l.LastCheckpoint
+}
+
+// parseInnodbRedoLog extracts the redo log statistics from the output of
+// SHOW ENGINE INNODB STATUS. It returns false if the section or its log
+// sequence numbers are missing.
+func parseInnodbRedoLog(status string) (innodbRedoLog, bool) {
+	var redoLog innodbRedoLog
+
+	section, ok := innodbStatusSection(status, "LOG")
+	if !ok {
+		return redoLog, false
+	}
+	lsn := innodbLogSequenceNumberRE.FindStringSubmatch(section)
+	checkpoint := innodbLogLastCheckpointRE.FindStringSubmatch(section)
+	if lsn == nil || checkpoint == nil {
+		return redoLog, false
+	}
+	redoLog.SequenceNumber, _ = strconv.ParseFloat(lsn[1], 64)
+	redoLog.LastCheckpoint, _ = strconv.ParseFloat(checkpoint[1], 64)
+	if match := innodbLogPendingRE.FindStringSubmatch(section); match != nil {
+		redoLog.PendingFlushes, _ = strconv.ParseFloat(match[1], 64)
+		redoLog.PendingCheckpointWrites, _ = strconv.ParseFloat(match[2], 64)
+		redoLog.HasPending = true
+	}
+	return redoLog, true
+}
+
+// ScrapeInnodbRedoLog scrapes the redo log statistics from `SHOW ENGINE INNODB STATUS`.
+type ScrapeInnodbRedoLog struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbRedoLog) Name() string {
+	return "engine_innodb_redo_log"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbRedoLog) Help() string {
+	return "Collect the log sequence number, checkpoint age and pending log writes from SHOW ENGINE INNODB STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbRedoLog) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbRedoLog) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	rows, err := queryContext(ctx, db, engineInnodbStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var typeCol, nameCol, statusCol string
+	if rows.Next() {
+		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
+			return err
+		}
+	}
+
+	redoLog, ok := parseInnodbRedoLog(statusCol)
+	if !ok {
+		return nil
+	}
+	ch <- prometheus.MustNewConstMetric(engineInnodbLogSequenceNumberDesc, prometheus.GaugeValue, redoLog.SequenceNumber)
+	ch <- prometheus.MustNewConstMetric(engineInnodbLogLastCheckpointDesc, prometheus.GaugeValue, redoLog.LastCheckpoint)
+	ch <- prometheus.MustNewConstMetric(engineInnodbLogCheckpointAgeDesc, prometheus.GaugeValue, redoLog.CheckpointAge())
+	if redoLog.HasPending {
+		ch <- prometheus.MustNewConstMetric(engineInnodbLogPendingDesc, prometheus.GaugeValue, redoLog.PendingFlushes, "log_flush")
+		ch <- prometheus.MustNewConstMetric(engineInnodbLogPendingDesc, prometheus.GaugeValue, redoLog.PendingCheckpointWrites, "checkpoint")
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbRedoLog{}
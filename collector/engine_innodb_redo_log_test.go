@@ -0,0 +1,139 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const innodbRedoLogSampleMySQL57 = `
+=====================================
+2019-07-10 10:25:01 0x7f1b2c1d0700 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 12 seconds
+---
+LOG
+---
+Log sequence number 2611439
+Log flushed up to   2611439
+Pages flushed up to 2609117
+Last checkpoint at  2601430
+1 pending log flushes, 0 pending chkp writes
+10 log i/o's done, 0.00 log i/o's/second
+----------------------
+BUFFER POOL AND MEMORY
+----------------------
+Total large memory allocated 137428992
+`
+
+const innodbRedoLogSampleMySQL80 = `
+=====================================
+2023-05-02 08:01:44 139770329556736 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 20 seconds
+---
+LOG
+---
+Log sequence number          31026030
+Log buffer assigned up to    31026030
+Log buffer completed up to   31026030
+Log written up to            31026030
+Log flushed up to            31026030
+Added dirty pages up to      31026030
+Pages flushed up to          31020010
+Last checkpoint at           31010030
+Log minimum file id is       6
+Log maximum file id is       9
+12 log i/o's done, 0.00 log i/o's/second
+----------------------
+BUFFER POOL AND MEMORY
+----------------------
+Total large memory allocated 0
+`
+
+func TestParseInnodbRedoLog(t *testing.T) {
+	convey.Convey("Parse log", t, func() {
+		convey.Convey("MySQL 5.7", func() {
+			redoLog, ok := parseInnodbRedoLog(innodbRedoLogSampleMySQL57)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(redoLog, convey.ShouldResemble, innodbRedoLog{
+				SequenceNumber:          2611439,
+				LastCheckpoint:          2601430,
+				PendingFlushes:          1,
+				PendingCheckpointWrites: 0,
+				HasPending:              true,
+			})
+			convey.So(redoLog.CheckpointAge(), convey.ShouldEqual, 10009)
+		})
+		convey.Convey("MySQL 8.0 without pending writes", func() {
+			redoLog, ok := parseInnodbRedoLog(innodbRedoLogSampleMySQL80)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(redoLog, convey.ShouldResemble, innodbRedoLog{
+				SequenceNumber: 31026030,
+				LastCheckpoint: 31010030,
+			})
+			convey.So(redoLog.CheckpointAge(), convey.ShouldEqual, 16000)
+		})
+		convey.Convey("No log section", func() {
+			_, ok := parseInnodbRedoLog(innodbBufferPoolSampleMySQL57)
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+	})
+}
+
+func TestScrapeInnodbRedoLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Type", "Name", "Status"}
+	mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("InnoDB", "", innodbRedoLogSampleMySQL57))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbRedoLog{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricsExpected := []MetricResult{
+		{labels: labelMap{}, value: 2611439, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 2601430, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 10009, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "log_flush"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "checkpoint"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricsExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
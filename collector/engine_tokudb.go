@@ -50,7 +50,7 @@ func (ScrapeEngineTokudbStatus) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeEngineTokudbStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	tokudbRows, err := db.QueryContext(ctx, engineTokudbStatusQuery)
+	tokudbRows, err := queryContext(ctx, db, engineTokudbStatusQuery)
 	if err != nil {
 		return err
 	}
@@ -61,6 +61,9 @@ func (ScrapeEngineTokudbStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<
 
 	for tokudbRows.Next() {
 		if err := tokudbRows.Scan(&temp, &key, &val); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		key = strings.ToLower(key)
@@ -16,9 +16,9 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -37,7 +37,7 @@ const (
 
 // SQL queries and parameters.
 const (
-	versionQuery = `SELECT @@version`
+	versionQuery = `SELECT @@version, @@version_comment`
 
 	// System variable params formatting.
 	// See: https://github.com/go-sql-driver/mysql#system-variables
@@ -45,10 +45,6 @@ const (
 	timeoutParam         = `lock_wait_timeout=%d`
 )
 
-var (
-	versionRE = regexp.MustCompile(`^\d+\.\d+`)
-)
-
 // Tunable flags.
 var (
 	exporterLockTimeout = kingpin.Flag(
@@ -59,6 +55,26 @@ var (
 		"exporter.log_slow_filter",
 		"Add a log_slow_filter to avoid slow query logging of scrapes. NOTE: Not supported by Oracle MySQL.",
 	).Default("false").Bool()
+	maxOpenConns = kingpin.Flag(
+		"mysql.max-open-conns",
+		"Maximum number of open connections to each database, shared by its scrapes.",
+	).Default("3").Int()
+	maxIdleConns = kingpin.Flag(
+		"mysql.max-idle-conns",
+		"Maximum number of idle connections to each database, shared by its scrapes.",
+	).Default("3").Int()
+	connMaxLifetime = kingpin.Flag(
+		"mysql.conn-max-lifetime",
+		"Maximum amount of time a connection to the database may be reused.",
+	).Default("1m").Duration()
+	maxConcurrentScrapes = kingpin.Flag(
+		"collect.max_concurrent",
+		"Maximum number of collectors scraping MySQL at the same time, 0 for no limit.",
+	).Default("0").Int()
+	scrapeJitter = kingpin.Flag(
+		"collect.scrape_jitter",
+		"Delay the first scrape of each collector by a random duration up to this, spreading the queries of exporters sharing a scrape interval over time, 0 to disable.",
+	).Default("0s").Duration()
 )
 
 // Metric descriptors.
@@ -68,6 +84,21 @@ var (
 		"Collector time duration.",
 		[]string{"collector"}, nil,
 	)
+	scrapeSuccessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_success"),
+		"Whether the last scrape of the collector succeeded (1 for success, 0 for error).",
+		[]string{"collector"}, nil,
+	)
+	scrapeVersionSkippedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_version_skipped"),
+		"Whether the collector was skipped because the server version is older than required (always 1).",
+		[]string{"collector"}, nil,
+	)
+	scrapeRequirementSkippedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_requirement_skipped"),
+		"Whether the collector was skipped because a server feature it requires is not available (always 1).",
+		[]string{"collector", "requirement"}, nil,
+	)
 )
 
 // Verify if Exporter implements prometheus.Collector
@@ -75,10 +106,11 @@ var _ prometheus.Collector = (*Exporter)(nil)
 
 // Exporter collects MySQL metrics. It implements prometheus.Collector.
 type Exporter struct {
-	ctx      context.Context
-	dsn      string
-	scrapers []Scraper
-	metrics  Metrics
+	ctx         context.Context
+	dsn         string
+	scrapers    []Scraper
+	metrics     Metrics
+	credentials CredentialProvider
 }
 
 // New returns a new MySQL exporter for the provided DSN.
@@ -105,12 +137,19 @@ func New(ctx context.Context, dsn string, metrics Metrics, scrapers []Scraper) *
 	}
 }
 
+// SetCredentialProvider makes the exporter take the password of each new
+// connection from provider instead of the DSN.
+func (e *Exporter) SetCredentialProvider(provider CredentialProvider) {
+	e.credentials = provider
+}
+
 // Describe implements prometheus.Collector.
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- e.metrics.TotalScrapes.Desc()
 	ch <- e.metrics.Error.Desc()
 	e.metrics.ScrapeErrors.Describe(ch)
 	ch <- e.metrics.MySQLUp.Desc()
+	ch <- e.metrics.Queries.Desc()
 }
 
 // Collect implements prometheus.Collector.
@@ -121,6 +160,41 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	ch <- e.metrics.Error
 	e.metrics.ScrapeErrors.Collect(ch)
 	ch <- e.metrics.MySQLUp
+	ch <- e.metrics.Queries
+}
+
+// configurePool applies the connection pool flags to db. The pool of a DSN
+// is shared by all its scrapes, so idle connections are reused across
+// scrapes for up to --mysql.conn-max-lifetime.
+func configurePool(db *sql.DB) {
+	db.SetMaxOpenConns(*maxOpenConns)
+	db.SetMaxIdleConns(*maxIdleConns)
+	db.SetConnMaxLifetime(*connMaxLifetime)
+}
+
+// pools holds the connection pool of each DSN, kept open for the lifetime
+// of the process.
+var pools = struct {
+	sync.Mutex
+	dbs map[string]*sql.DB
+}{dbs: map[string]*sql.DB{}}
+
+// pool returns the connection pool of the DSN of the exporter, opening it
+// on the first scrape. The credential provider of that first exporter
+// serves all new connections of the pool.
+func (e *Exporter) pool() (*sql.DB, error) {
+	pools.Lock()
+	defer pools.Unlock()
+	if db, ok := pools.dbs[e.dsn]; ok {
+		return db, nil
+	}
+	db, err := OpenDB(e.dsn, e.credentials)
+	if err != nil {
+		return nil, err
+	}
+	configurePool(db)
+	pools.dbs[e.dsn] = db
+	return db, nil
 }
 
 func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) {
@@ -128,19 +202,12 @@ func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) {
 	var err error
 
 	scrapeTime := time.Now()
-	db, err := sql.Open("mysql", e.dsn)
+	db, err := e.pool()
 	if err != nil {
 		log.Errorln("Error opening connection to database:", err)
 		e.metrics.Error.Set(1)
 		return
 	}
-	defer db.Close()
-
-	// By design exporter should use maximum one connection per request.
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-	// Set max lifetime for a connection.
-	db.SetConnMaxLifetime(1 * time.Minute)
 
 	if err := db.PingContext(ctx); err != nil {
 		log.Errorln("Error pinging mysqld:", err)
@@ -154,40 +221,197 @@ func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) {
 
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "connection")
 
-	version := getMySQLVersion(db)
+	e.scrapeAll(ctx, db, ch)
+}
+
+// scrapeAll runs the scrapers supported by the server version concurrently,
+// at most --collect.max_concurrent at a time. The first scrape of each
+// scraper starts after a random delay of up to --collect.scrape_jitter.
+func (e *Exporter) scrapeAll(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) {
+	serverVersion := getServerVersion(db)
+	version := serverVersion.number()
+	ctx = withServerVersion(ctx, serverVersion)
+	ctx = withQueryCounter(ctx, e.metrics.Queries)
+	ctx = withTarget(ctx, e.dsn)
+	ctx = withRetries(ctx, *retryTransient)
+	if *resetDetection {
+		ctx = withServerUUID(ctx, getServerUUID(ctx, db))
+	}
+	var features serverFeatures
+	if needsFeatures(e.scrapers) {
+		features = getServerFeatures(ctx, e.dsn, db)
+	}
+	// limit holds a token for each running scraper when the number of
+	// concurrent scrapers is limited.
+	var limit chan struct{}
+	if *maxConcurrentScrapes > 0 {
+		limit = make(chan struct{}, *maxConcurrentScrapes)
+	}
+	jitter := *scrapeJitter
 	var wg sync.WaitGroup
 	defer wg.Wait()
 	for _, scraper := range e.scrapers {
 		if version < scraper.Version() {
+			ch <- prometheus.MustNewConstMetric(scrapeVersionSkippedDesc, prometheus.GaugeValue, 1, "collect."+scraper.Name())
+			continue
+		}
+		if feature := features.missing(scraper); feature != "" {
+			ch <- prometheus.MustNewConstMetric(scrapeRequirementSkippedDesc, prometheus.GaugeValue, 1, "collect."+scraper.Name(), feature)
 			continue
 		}
 
 		wg.Add(1)
 		go func(scraper Scraper) {
 			defer wg.Done()
-			label := "collect." + scraper.Name()
-			scrapeTime := time.Now()
-			if err := scraper.Scrape(ctx, db, ch); err != nil {
-				log.Errorln("Error scraping for "+label+":", err)
-				e.metrics.ScrapeErrors.WithLabelValues(label).Inc()
-				e.metrics.Error.Set(1)
+			if jitter > 0 && firstScrape(e.dsn+"/"+scraper.Name()) {
+				waitJitter(ctx, jitter)
+				if ctx.Err() != nil {
+					return
+				}
+			}
+			if limit != nil {
+				limit <- struct{}{}
+				defer func() { <-limit }()
 			}
-			ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
+			e.scrapeOne(ctx, db, scraper, ch)
 		}(scraper)
 	}
 }
 
-func getMySQLVersion(db *sql.DB) float64 {
-	var versionStr string
-	var versionNum float64
-	if err := db.QueryRow(versionQuery).Scan(&versionStr); err == nil {
-		versionNum, _ = strconv.ParseFloat(versionRE.FindString(versionStr), 64)
+// jitteredScrapes holds the collectors already scraped once, keyed by DSN
+// and collector name, as only their first scrape is delayed by the jitter.
+var jitteredScrapes = struct {
+	sync.Mutex
+	scraped map[string]bool
+}{scraped: map[string]bool{}}
+
+// firstScrape reports whether the collector identified by key is scraped
+// for the first time.
+func firstScrape(key string) bool {
+	jitteredScrapes.Lock()
+	defer jitteredScrapes.Unlock()
+	if jitteredScrapes.scraped[key] {
+		return false
+	}
+	jitteredScrapes.scraped[key] = true
+	return true
+}
+
+// jitterDelay returns a random duration in [0, max).
+func jitterDelay(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// waitJitter waits for a random duration up to max, or until ctx is done.
+func waitJitter(ctx context.Context, max time.Duration) {
+	delay := jitterDelay(max)
+	if delay == 0 {
+		return
 	}
-	// If we can't match/parse the version, set it some big value that matches all versions.
-	if versionNum == 0 {
-		versionNum = 999
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
 	}
-	return versionNum
+}
+
+// scrapeError is an error returned by a scraper, annotated with the name of
+// the scraper.
+type scrapeError struct {
+	collector string
+	err       error
+}
+
+func (e *scrapeError) Error() string {
+	return e.collector + ": " + e.err.Error()
+}
+
+// Unwrap returns the error returned by the scraper.
+func (e *scrapeError) Unwrap() error {
+	return e.err
+}
+
+// scrape runs the scraper, wrapping its error in a scrapeError.
+func scrape(ctx context.Context, db *sql.DB, scraper Scraper, ch chan<- prometheus.Metric) error {
+	if err := scraper.Scrape(ctx, db, ch); err != nil {
+		return &scrapeError{collector: scraper.Name(), err: err}
+	}
+	return nil
+}
+
+// logScrapeError logs err with the name of the failed collector as a field.
+func logScrapeError(err error) {
+	var serr *scrapeError
+	if errors.As(err, &serr) {
+		log.With("collector", serr.collector).Errorln("Error scraping:", serr.err)
+		return
+	}
+	log.Errorln("Error scraping:", err)
+}
+
+// scrapeOne runs a single scraper and reports its duration and outcome.
+// Sampled scrapers only run once every IntervalFactor() scrapes, the metrics
+// of their last run are sent again in between.
+func (e *Exporter) scrapeOne(ctx context.Context, db *sql.DB, scraper Scraper, ch chan<- prometheus.Metric) {
+	factor := intervalFactor(scraper)
+	if factor == 1 {
+		e.runScraper(ctx, db, scraper, ch)
+		return
+	}
+	key := e.dsn + "/" + scraper.Name()
+	if metrics, ok := cachedScrape(key, factor); ok {
+		for _, m := range metrics {
+			ch <- m
+		}
+		return
+	}
+
+	var metrics []prometheus.Metric
+	scraperCh := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for m := range scraperCh {
+			metrics = append(metrics, m)
+			ch <- m
+		}
+	}()
+	e.runScraper(ctx, db, scraper, scraperCh)
+	close(scraperCh)
+	<-done
+	storeScrape(key, metrics)
+}
+
+// runScraper runs the scraper, reporting its duration and outcome.
+func (e *Exporter) runScraper(ctx context.Context, db *sql.DB, scraper Scraper, ch chan<- prometheus.Metric) {
+	label := "collect." + scraper.Name()
+	scrapeTime := time.Now()
+	success := 1.0
+	ctx = withScanErrors(ctx, label, e.metrics.ScrapeErrors.WithLabelValues(label))
+	if err := scrape(ctx, db, scraper, ch); err != nil {
+		logScrapeError(err)
+		e.metrics.ScrapeErrors.WithLabelValues(label).Inc()
+		e.metrics.Error.Set(1)
+		success = 0
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, label)
+}
+
+func getMySQLVersion(db *sql.DB) float64 {
+	return getServerVersion(db).number()
+}
+
+func getServerVersion(db *sql.DB) serverVersion {
+	var versionStr, versionComment string
+	if err := db.QueryRow(versionQuery).Scan(&versionStr, &versionComment); err != nil {
+		return serverVersion{}
+	}
+	return parseServerVersion(versionStr, versionComment)
 }
 
 // Metrics represents exporter metrics which values can be carried between http requests.
@@ -196,6 +420,7 @@ type Metrics struct {
 	ScrapeErrors *prometheus.CounterVec
 	Error        prometheus.Gauge
 	MySQLUp      prometheus.Gauge
+	Queries      prometheus.Counter
 }
 
 // NewMetrics creates new Metrics instance.
@@ -225,5 +450,11 @@ func NewMetrics() Metrics {
 			Name:      "up",
 			Help:      "Whether the MySQL server is up.",
 		}),
+		Queries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queries_total",
+			Help:      "Total number of queries the exporter issued to MySQL.",
+		}),
 	}
 }
@@ -16,11 +16,18 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const dsn = "root@/mysql"
@@ -78,3 +85,384 @@ func TestGetMySQLVersion(t *testing.T) {
 		convey.So(getMySQLVersion(db), convey.ShouldBeBetweenOrEqual, 5.5, 10.3)
 	})
 }
+
+// stubScraper is a Scraper returning a fixed error without touching the database.
+type stubScraper struct {
+	name    string
+	err     error
+	version float64
+}
+
+func (s stubScraper) Name() string { return s.name }
+func (s stubScraper) Help() string { return "Stub scraper" }
+func (s stubScraper) Version() float64 {
+	if s.version == 0 {
+		return 5.1
+	}
+	return s.version
+}
+func (s stubScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	return s.err
+}
+
+func TestScrapeOneSuccess(t *testing.T) {
+	exporter := New(context.Background(), dsn, NewMetrics(), nil)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeOne(context.Background(), nil, stubScraper{name: "ok"}, ch)
+		exporter.scrapeOne(context.Background(), nil, stubScraper{name: "failing", err: errors.New("boom")}, ch)
+		close(ch)
+	}()
+
+	success := map[string]float64{}
+	durations := 0
+	for m := range ch {
+		got := readMetric(m)
+		switch {
+		case strings.Contains(m.Desc().String(), "collector_success"):
+			success[got.labels["collector"]] = got.value
+		case strings.Contains(m.Desc().String(), "collector_duration_seconds"):
+			durations++
+		}
+	}
+
+	convey.Convey("Collector success metrics", t, func() {
+		convey.So(durations, convey.ShouldEqual, 2)
+		convey.So(success, convey.ShouldResemble, map[string]float64{
+			"collect.ok":      1,
+			"collect.failing": 0,
+		})
+	})
+}
+
+func TestScrapeError(t *testing.T) {
+	driverErr := &mysqldriver.MySQLError{Number: 1146, Message: "Table 'sys.x$user_summary_by_statement_type' doesn't exist"}
+	scraper := stubScraper{name: "sys.user_summary_by_statement_type", err: driverErr}
+
+	convey.Convey("Scrape errors carry the collector name", t, func() {
+		err := scrape(context.Background(), nil, scraper, nil)
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldEqual, "sys.user_summary_by_statement_type: "+driverErr.Error())
+
+		var serr *scrapeError
+		convey.So(errors.As(err, &serr), convey.ShouldBeTrue)
+		convey.So(serr.collector, convey.ShouldEqual, "sys.user_summary_by_statement_type")
+
+		var mysqlErr *mysqldriver.MySQLError
+		convey.So(errors.As(err, &mysqlErr), convey.ShouldBeTrue)
+		convey.So(mysqlErr.Number, convey.ShouldEqual, 1146)
+		convey.So(errors.Unwrap(err), convey.ShouldEqual, driverErr)
+	})
+	convey.Convey("Successful scrapes return no error", t, func() {
+		convey.So(scrape(context.Background(), nil, stubScraper{name: "ok"}, nil), convey.ShouldBeNil)
+	})
+}
+
+func TestConfigurePool(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--mysql.max-open-conns", "5",
+		"--mysql.max-idle-conns", "4",
+		"--mysql.conn-max-lifetime", "30s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	configurePool(db)
+
+	convey.Convey("Pool settings applied", t, func() {
+		convey.So(db.Stats().MaxOpenConnections, convey.ShouldEqual, 5)
+		convey.So(*maxIdleConns, convey.ShouldEqual, 4)
+		convey.So(connMaxLifetime.String(), convey.ShouldEqual, "30s")
+	})
+}
+
+func TestConfigurePoolDefaults(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	configurePool(db)
+
+	convey.Convey("Default pool settings", t, func() {
+		convey.So(db.Stats().MaxOpenConnections, convey.ShouldEqual, 3)
+		convey.So(*maxIdleConns, convey.ShouldEqual, 3)
+		convey.So(connMaxLifetime.String(), convey.ShouldEqual, "1m0s")
+	})
+}
+
+func TestExporterPool(t *testing.T) {
+	newExporter := func(dsn string) *Exporter {
+		return &Exporter{dsn: dsn}
+	}
+
+	convey.Convey("Connection pools", t, func() {
+		first, err := newExporter("pool@tcp(a:3306)/").pool()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(first.Stats().MaxOpenConnections, convey.ShouldEqual, *maxOpenConns)
+
+		convey.Convey("Are reused by the scrapes of a DSN", func() {
+			again, err := newExporter("pool@tcp(a:3306)/").pool()
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(again, convey.ShouldEqual, first)
+		})
+		convey.Convey("Are not shared between DSNs", func() {
+			other, err := newExporter("pool@tcp(b:3306)/").pool()
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(other, convey.ShouldNotEqual, first)
+		})
+	})
+}
+
+func TestScrapeAllVersionSkipped(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow("5.5.62-log", "MySQL Community Server (GPL)"))
+
+	exporter := New(context.Background(), dsn, NewMetrics(), []Scraper{
+		stubScraper{name: "old"},
+		stubScraper{name: "new", version: 5.7},
+	})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeAll(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	skipped := map[string]float64{}
+	scraped := map[string]bool{}
+	for m := range ch {
+		got := readMetric(m)
+		switch {
+		case strings.Contains(m.Desc().String(), "collector_version_skipped"):
+			skipped[got.labels["collector"]] = got.value
+		case strings.Contains(m.Desc().String(), "collector_success"):
+			scraped[got.labels["collector"]] = true
+		}
+	}
+
+	convey.Convey("Collectors newer than the server are reported as skipped", t, func() {
+		convey.So(skipped, convey.ShouldResemble, map[string]float64{"collect.new": 1})
+		convey.So(scraped, convey.ShouldResemble, map[string]bool{"collect.old": true})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// concurrencyScraper records the highest number of its instances scraping at
+// the same time.
+type concurrencyScraper struct {
+	name  string
+	state *concurrencyState
+}
+
+type concurrencyState struct {
+	sync.Mutex
+	running, max int
+}
+
+func (s concurrencyScraper) Name() string     { return s.name }
+func (s concurrencyScraper) Help() string     { return "Concurrency scraper" }
+func (s concurrencyScraper) Version() float64 { return 5.1 }
+func (s concurrencyScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	s.state.Lock()
+	s.state.running++
+	if s.state.running > s.state.max {
+		s.state.max = s.state.running
+	}
+	s.state.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.state.Lock()
+	s.state.running--
+	s.state.Unlock()
+	return nil
+}
+
+func TestScrapeAllMaxConcurrent(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.max_concurrent", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow("8.0.33", "MySQL Community Server - GPL"))
+
+	state := &concurrencyState{}
+	var scrapers []Scraper
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		scrapers = append(scrapers, concurrencyScraper{name: name, state: state})
+	}
+	exporter := New(context.Background(), dsn, NewMetrics(), scrapers)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeAll(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	scraped := 0
+	for m := range ch {
+		if strings.Contains(m.Desc().String(), "collector_success") {
+			scraped++
+		}
+	}
+
+	convey.Convey("At most collect.max_concurrent collectors scrape at once", t, func() {
+		convey.So(scraped, convey.ShouldEqual, 6)
+		convey.So(state.max, convey.ShouldEqual, 2)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestJitterDelay(t *testing.T) {
+	convey.Convey("Jitter delays are within bounds", t, func() {
+		convey.So(jitterDelay(0), convey.ShouldEqual, 0)
+		for i := 0; i < 1000; i++ {
+			delay := jitterDelay(50 * time.Millisecond)
+			convey.So(delay, convey.ShouldBeGreaterThanOrEqualTo, 0)
+			convey.So(delay, convey.ShouldBeLessThan, 50*time.Millisecond)
+		}
+	})
+	convey.Convey("Jitter waits stop when the context is done", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		start := time.Now()
+		waitJitter(ctx, time.Hour)
+		convey.So(time.Since(start), convey.ShouldBeLessThan, time.Second)
+	})
+}
+
+func TestScrapeAllJitter(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.scrape_jitter", "50ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow("8.0.33", "MySQL Community Server - GPL"))
+
+	var scrapers []Scraper
+	for _, name := range []string{"a", "b", "c", "d"} {
+		scrapers = append(scrapers, stubScraper{name: name})
+	}
+	exporter := New(context.Background(), dsn, NewMetrics(), scrapers)
+
+	ch := make(chan prometheus.Metric)
+	start := time.Now()
+	go func() {
+		exporter.scrapeAll(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	scraped := 0
+	for m := range ch {
+		if strings.Contains(m.Desc().String(), "collector_success") {
+			scraped++
+		}
+	}
+	elapsed := time.Since(start)
+
+	convey.Convey("Collectors start within the jitter", t, func() {
+		convey.So(scraped, convey.ShouldEqual, 4)
+		convey.So(elapsed, convey.ShouldBeLessThan, time.Second)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeAllJitterOnce(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.scrape_jitter", "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow("8.0.33", "MySQL Community Server - GPL"))
+	}
+
+	exporter := New(context.Background(), dsn, NewMetrics(), []Scraper{stubScraper{name: "jitter_once"}})
+	scrape := func(ctx context.Context) int {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.scrapeAll(ctx, db, ch)
+			close(ch)
+		}()
+		scraped := 0
+		for m := range ch {
+			if strings.Contains(m.Desc().String(), "collector_success") {
+				scraped++
+			}
+		}
+		return scraped
+	}
+
+	convey.Convey("Collectors are skipped when the context is done during the jitter", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		convey.So(scrape(ctx), convey.ShouldEqual, 0)
+	})
+	convey.Convey("Only the first scrape is delayed", t, func() {
+		start := time.Now()
+		convey.So(scrape(context.Background()), convey.ShouldEqual, 1)
+		convey.So(time.Since(start), convey.ShouldBeLessThan, time.Second)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
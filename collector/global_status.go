@@ -99,12 +99,13 @@ func (ScrapeGlobalStatus) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	globalStatusRows, err := db.QueryContext(ctx, globalStatusQuery)
+	globalStatusRows, err := queryContext(ctx, db, globalStatusQuery)
 	if err != nil {
 		return err
 	}
 	defer globalStatusRows.Close()
 
+	resets := newResetTracker(ctx, ScrapeGlobalStatus{}.Name())
 	var key string
 	var val sql.RawBytes
 	var textItems = map[string]string{
@@ -116,6 +117,9 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 
 	for globalStatusRows.Next() {
 		if err := globalStatusRows.Scan(&key, &val); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		if floatVal, ok := parseStatus(val); ok { // Unparsable values are silently skipped.
@@ -131,14 +135,17 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 			}
 			switch match[1] {
 			case "com":
+				resets.observe(key, floatVal)
 				ch <- prometheus.MustNewConstMetric(
 					globalCommandsDesc, prometheus.CounterValue, floatVal, match[2],
 				)
 			case "handler":
+				resets.observe(key, floatVal)
 				ch <- prometheus.MustNewConstMetric(
 					globalHandlerDesc, prometheus.CounterValue, floatVal, match[2],
 				)
 			case "connection_errors":
+				resets.observe(key, floatVal)
 				ch <- prometheus.MustNewConstMetric(
 					globalConnectionErrorsDesc, prometheus.CounterValue, floatVal, match[2],
 				)
@@ -155,15 +162,18 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 				case "total":
 					continue
 				default:
+					resets.observe(key, floatVal)
 					ch <- prometheus.MustNewConstMetric(
 						globalBufferPoolPageChangesDesc, prometheus.CounterValue, floatVal, match[2],
 					)
 				}
 			case "innodb_rows":
+				resets.observe(key, floatVal)
 				ch <- prometheus.MustNewConstMetric(
 					globalInnoDBRowOpsDesc, prometheus.CounterValue, floatVal, match[2],
 				)
 			case "performance_schema":
+				resets.observe(key, floatVal)
 				ch <- prometheus.MustNewConstMetric(
 					globalPerformanceSchemaLostDesc, prometheus.CounterValue, floatVal, match[2],
 				)
@@ -221,6 +231,7 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 		}
 	}
 
+	resets.collect(ch)
 	return nil
 }
 
@@ -109,3 +109,54 @@ func TestScrapeGlobalStatus(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeGlobalStatusPrefixLabels(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// Compression_* variables share the "com" prefix without being commands.
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Com_stmt_execute", "21").
+		AddRow("Compression", "OFF").
+		AddRow("Compression_algorithm", "zlib").
+		AddRow("Handler_read_rnd_next", "1783").
+		AddRow("Handler_savepoint_rollback", "0")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGlobalStatus{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	type namedResult struct {
+		name string
+		MetricResult
+	}
+	metricExpected := []namedResult{
+		{"mysql_global_status_commands_total", MetricResult{labels: labelMap{"command": "stmt_execute"}, value: 21, metricType: dto.MetricType_COUNTER}},
+		{"mysql_global_status_compression", MetricResult{labels: labelMap{}, value: 0, metricType: dto.MetricType_UNTYPED}},
+		{"mysql_global_status_handlers_total", MetricResult{labels: labelMap{"handler": "read_rnd_next"}, value: 1783, metricType: dto.MetricType_COUNTER}},
+		{"mysql_global_status_handlers_total", MetricResult{labels: labelMap{"handler": "savepoint_rollback"}, value: 0, metricType: dto.MetricType_COUNTER}},
+	}
+	convey.Convey("Com_ and Handler_ prefixes become labels", t, func() {
+		for _, expect := range metricExpected {
+			m := <-ch
+			convey.So(m.Desc().String(), convey.ShouldContainSubstring, `fqName: "`+expect.name+`"`)
+			convey.So(readMetric(m), convey.ShouldResemble, expect.MetricResult)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
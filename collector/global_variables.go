@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const (
@@ -32,6 +33,14 @@ const (
 	globalVariablesQuery = `SHOW GLOBAL VARIABLES`
 )
 
+// Tunable flags.
+var (
+	globalVariablesInclude = kingpin.Flag(
+		"collect.global_variables.include",
+		"RegEx filter for the names of the variables collected as gauges from SHOW GLOBAL VARIABLES",
+	).Default(".*").String()
+)
+
 var (
 	// Map known global variables to help strings. Unknown will be mapped to generic gauges.
 	globalVariablesHelp = map[string]string{
@@ -138,7 +147,11 @@ func (ScrapeGlobalVariables) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	globalVariablesRows, err := db.QueryContext(ctx, globalVariablesQuery)
+	include, err := regexp.Compile(*globalVariablesInclude)
+	if err != nil {
+		return err
+	}
+	globalVariablesRows, err := queryContext(ctx, db, globalVariablesQuery)
 	if err != nil {
 		return err
 	}
@@ -156,11 +169,17 @@ func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 
 	for globalVariablesRows.Next() {
 		if err = globalVariablesRows.Scan(&key, &val); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
 		key = validPrometheusName(key)
 		if floatVal, ok := parseStatus(val); ok {
+			if !include.MatchString(key) {
+				continue
+			}
 			help := globalVariablesHelp[key]
 			if help == "" {
 				help = "Generic gauge metric from SHOW GLOBAL VARIABLES."
@@ -87,7 +87,7 @@ func (ScrapeHeartbeat) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeHeartbeat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	query := fmt.Sprintf(heartbeatQuery, *collectHeartbeatDatabase, *collectHeartbeatTable)
-	heartbeatRows, err := db.QueryContext(ctx, query)
+	heartbeatRows, err := queryContext(ctx, db, query)
 	if err != nil {
 		return err
 	}
@@ -100,6 +100,9 @@ func (ScrapeHeartbeat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 
 	for heartbeatRows.Next() {
 		if err := heartbeatRows.Scan(&ts, &now, &serverId); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
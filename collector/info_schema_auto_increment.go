@@ -70,7 +70,7 @@ func (ScrapeAutoIncrementColumns) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	autoIncrementRows, err := db.QueryContext(ctx, infoSchemaAutoIncrementQuery)
+	autoIncrementRows, err := queryContext(ctx, db, infoSchemaAutoIncrementQuery)
 	if err != nil {
 		return err
 	}
@@ -85,6 +85,9 @@ func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, db *sql.DB, ch cha
 		if err := autoIncrementRows.Scan(
 			&schema, &table, &column, &value, &max,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
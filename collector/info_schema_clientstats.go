@@ -162,7 +162,7 @@ func (ScrapeClientStat) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeClientStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	var varName, varVal string
-	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
+	err := queryRowContext(ctx, db, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		log.Debugln("Detailed client stats are not available.")
 		return nil
@@ -172,7 +172,7 @@ func (ScrapeClientStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		return nil
 	}
 
-	informationSchemaClientStatisticsRows, err := db.QueryContext(ctx, clientStatQuery)
+	informationSchemaClientStatisticsRows, err := queryContext(ctx, db, clientStatQuery)
 	if err != nil {
 		return err
 	}
@@ -200,6 +200,9 @@ func (ScrapeClientStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 
 	for informationSchemaClientStatisticsRows.Next() {
 		if err := informationSchemaClientStatisticsRows.Scan(clientStatScanArgs...); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
@@ -0,0 +1,102 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the foreign keys from `information_schema.key_column_usage`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// infoSchemaForeignKeysQuery counts constraints rather than rows, as a
+// foreign key has a row for each of its columns.
+const infoSchemaForeignKeysQuery = `
+	SELECT TABLE_SCHEMA, TABLE_NAME, COUNT(DISTINCT CONSTRAINT_NAME)
+	  FROM information_schema.key_column_usage
+	  WHERE REFERENCED_TABLE_NAME IS NOT NULL
+	    AND TABLE_SCHEMA REGEXP ?
+	    AND TABLE_SCHEMA NOT REGEXP ?
+	  GROUP BY TABLE_SCHEMA, TABLE_NAME
+	`
+
+// Tunable flags.
+var (
+	infoSchemaForeignKeysInclude = kingpin.Flag(
+		"collect.info_schema.foreign_keys.schema_include",
+		"RegEx of schemas to count foreign keys for",
+	).Default(".*").String()
+	infoSchemaForeignKeysExclude = kingpin.Flag(
+		"collect.info_schema.foreign_keys.schema_exclude",
+		"RegEx of schemas to skip when counting foreign keys",
+	).Default("^(mysql|performance_schema|information_schema|sys)$").String()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaForeignKeysDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "foreign_keys"),
+		"The number of foreign key constraints by table.",
+		[]string{"schema", "table"}, nil,
+	)
+)
+
+// ScrapeForeignKeys collects the foreign keys from `information_schema.key_column_usage`.
+type ScrapeForeignKeys struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeForeignKeys) Name() string {
+	return informationSchema + ".foreign_keys"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeForeignKeys) Help() string {
+	return "Collect the number of foreign keys per table from information_schema.key_column_usage"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeForeignKeys) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeForeignKeys) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	foreignKeysRows, err := queryContext(ctx, db, infoSchemaForeignKeysQuery,
+		*infoSchemaForeignKeysInclude, *infoSchemaForeignKeysExclude)
+	if err != nil {
+		return err
+	}
+	defer foreignKeysRows.Close()
+
+	var (
+		schema, table string
+		count         uint64
+	)
+	for foreignKeysRows.Next() {
+		if err := foreignKeysRows.Scan(&schema, &table, &count); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaForeignKeysDesc, prometheus.GaugeValue, float64(count), schema, table)
+	}
+	return foreignKeysRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeForeignKeys{}
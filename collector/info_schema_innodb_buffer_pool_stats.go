@@ -0,0 +1,124 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.INNODB_BUFFER_POOL_STATS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const innodbBufferPoolStatsQuery = `
+	SELECT
+	    POOL_ID, POOL_SIZE, FREE_BUFFERS, DATABASE_PAGES, MODIFIED_DATABASE_PAGES,
+	    PAGES_MADE_YOUNG, NUMBER_PAGES_READ, HIT_RATE
+	  FROM information_schema.innodb_buffer_pool_stats
+	`
+
+// Metric descriptors.
+var (
+	infoSchemaInnodbBufferPoolStatsPoolSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_pool_size_pages"),
+		"The size of the buffer pool instance in pages.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolStatsFreeBuffersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_free_buffers"),
+		"The number of free pages in the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolStatsDatabasePagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_database_pages"),
+		"The number of pages in the buffer pool instance containing data.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolStatsModifiedPagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_modified_database_pages"),
+		"The number of modified pages in the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolStatsPagesMadeYoungDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_pages_made_young_total"),
+		"The number of pages made young in the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolStatsPagesReadDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_pages_read_total"),
+		"The number of pages read into the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolStatsHitRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_stats_hit_rate"),
+		"Ratio of page gets served from the buffer pool instance since the last InnoDB monitor printout.",
+		[]string{"pool_id"}, nil,
+	)
+)
+
+// ScrapeBufferPoolStats collects from `information_schema.innodb_buffer_pool_stats`.
+type ScrapeBufferPoolStats struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeBufferPoolStats) Name() string {
+	return informationSchema + ".innodb_buffer_pool_stats"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeBufferPoolStats) Help() string {
+	return "Collect per instance buffer pool metrics from information_schema.innodb_buffer_pool_stats"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeBufferPoolStats) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeBufferPoolStats) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	bufferPoolStatsRows, err := queryContext(ctx, db, innodbBufferPoolStatsQuery)
+	if err != nil {
+		return err
+	}
+	defer bufferPoolStatsRows.Close()
+
+	var (
+		poolID                                         string
+		poolSize, freeBuffers, databasePages           float64
+		modifiedPages, pagesMadeYoung, pagesRead, rate float64
+	)
+	for bufferPoolStatsRows.Next() {
+		if err := bufferPoolStatsRows.Scan(
+			&poolID, &poolSize, &freeBuffers, &databasePages, &modifiedPages, &pagesMadeYoung, &pagesRead, &rate,
+		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsPoolSizeDesc, prometheus.GaugeValue, poolSize, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsFreeBuffersDesc, prometheus.GaugeValue, freeBuffers, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsDatabasePagesDesc, prometheus.GaugeValue, databasePages, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsModifiedPagesDesc, prometheus.GaugeValue, modifiedPages, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsPagesMadeYoungDesc, prometheus.CounterValue, pagesMadeYoung, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsPagesReadDesc, prometheus.CounterValue, pagesRead, poolID)
+		// HIT_RATE is reported per thousand page gets.
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolStatsHitRateDesc, prometheus.GaugeValue, rate/1000, poolID)
+	}
+	return bufferPoolStatsRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeBufferPoolStats{}
@@ -0,0 +1,77 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeBufferPoolStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"POOL_ID", "POOL_SIZE", "FREE_BUFFERS", "DATABASE_PAGES", "MODIFIED_DATABASE_PAGES",
+		"PAGES_MADE_YOUNG", "NUMBER_PAGES_READ", "HIT_RATE"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("0", "8191", "1024", "7000", "120", "3310", "45012", "998").
+		AddRow("1", "8192", "2048", "6100", "0", "2007", "38210", "1000")
+	mock.ExpectQuery(sanitizeQuery(innodbBufferPoolStatsQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeBufferPoolStats{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"pool_id": "0"}, value: 8191, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 1024, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 7000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 120, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 3310, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "0"}, value: 45012, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "0"}, value: 0.998, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 8192, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 2048, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 6100, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 2007, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "1"}, value: 38210, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "1"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -77,7 +77,7 @@ func (ScrapeInnodbCmp) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeInnodbCmp) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	informationSchemaInnodbCmpRows, err := db.QueryContext(ctx, innodbCmpQuery)
+	informationSchemaInnodbCmpRows, err := queryContext(ctx, db, innodbCmpQuery)
 	if err != nil {
 		return err
 	}
@@ -92,6 +92,9 @@ func (ScrapeInnodbCmp) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 		if err := informationSchemaInnodbCmpRows.Scan(
 			&page_size, &compress_ops, &compress_ops_ok, &compress_time, &uncompress_ops, &uncompress_time,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
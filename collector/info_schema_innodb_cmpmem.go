@@ -30,13 +30,13 @@ const innodbCmpMemQuery = `
 
 // Metric descriptors.
 var (
-	infoSchemaInnodbCmpMemPagesRead = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, informationSchema, "innodb_cmpmem_pages_used_total"),
+	infoSchemaInnodbCmpMemPagesUsed = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_cmpmem_pages_used"),
 		"Number of blocks of the size PAGE_SIZE that are currently in use.",
 		[]string{"page_size", "buffer_pool"}, nil,
 	)
 	infoSchemaInnodbCmpMemPagesFree = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, informationSchema, "innodb_cmpmem_pages_free_total"),
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_cmpmem_pages_free"),
 		"Number of blocks of the size PAGE_SIZE that are currently available for allocation.",
 		[]string{"page_size", "buffer_pool"}, nil,
 	)
@@ -72,7 +72,7 @@ func (ScrapeInnodbCmpMem) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeInnodbCmpMem) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	informationSchemaInnodbCmpMemRows, err := db.QueryContext(ctx, innodbCmpMemQuery)
+	informationSchemaInnodbCmpMemRows, err := queryContext(ctx, db, innodbCmpMemQuery)
 	if err != nil {
 		return err
 	}
@@ -87,11 +87,14 @@ func (ScrapeInnodbCmpMem) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 		if err := informationSchemaInnodbCmpMemRows.Scan(
 			&page_size, &buffer_pool, &pages_used, &pages_free, &relocation_ops, &relocation_time,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
-		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbCmpMemPagesRead, prometheus.CounterValue, pages_used, page_size, buffer_pool)
-		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbCmpMemPagesFree, prometheus.CounterValue, pages_free, page_size, buffer_pool)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbCmpMemPagesUsed, prometheus.GaugeValue, pages_used, page_size, buffer_pool)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbCmpMemPagesFree, prometheus.GaugeValue, pages_free, page_size, buffer_pool)
 		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbCmpMemRelocationOps, prometheus.CounterValue, relocation_ops, page_size, buffer_pool)
 		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbCmpMemRelocationTime, prometheus.CounterValue, (relocation_time / 1000), page_size, buffer_pool)
 	}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const infoSchemaInnodbMetricsQuery = `
@@ -30,8 +31,17 @@ const infoSchemaInnodbMetricsQuery = `
 		  count
 		  FROM information_schema.innodb_metrics
 		  WHERE status = 'enabled'
+		    AND subsystem REGEXP ?
 		`
 
+// Tunable flags.
+var (
+	innodbMetricsSubsystemInclude = kingpin.Flag(
+		"collect.info_schema.innodb_metrics.subsystem_include",
+		"RegEx subsystem filter for information_schema.innodb_metrics",
+	).Default(".*").String()
+)
+
 // Metrics descriptors.
 var (
 	infoSchemaBufferPageReadTotalDesc = prometheus.NewDesc(
@@ -82,7 +92,7 @@ func (ScrapeInnodbMetrics) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeInnodbMetrics) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	innodbMetricsRows, err := db.QueryContext(ctx, infoSchemaInnodbMetricsQuery)
+	innodbMetricsRows, err := queryContext(ctx, db, infoSchemaInnodbMetricsQuery, *innodbMetricsSubsystemInclude)
 	if err != nil {
 		return err
 	}
@@ -97,6 +107,9 @@ func (ScrapeInnodbMetrics) Scrape(ctx context.Context, db *sql.DB, ch chan<- pro
 		if err := innodbMetricsRows.Scan(
 			&name, &subsystem, &metricType, &comment, &value,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		// Special handling of the "buffer_page_io" subsystem.
@@ -50,7 +50,7 @@ func TestScrapeInnodbMetrics(t *testing.T) {
 		AddRow("buffer_pool_pages_data", "buffer", "gauge", "Number of data buffer pool pages", 6).
 		AddRow("buffer_pool_pages_total", "buffer", "gauge", "Number of total buffer pool pages", 7).
 		AddRow("NOPE", "buffer_page_io", "counter", "An invalid buffer_page_io metric", 999)
-	mock.ExpectQuery(sanitizeQuery(infoSchemaInnodbMetricsQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(infoSchemaInnodbMetricsQuery)).WithArgs(".*").WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -81,3 +81,41 @@ func TestScrapeInnodbMetrics(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeInnodbMetricsSubsystemInclude(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.innodb_metrics.subsystem_include", "^(lock|server)$",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"name", "subsystem", "type", "comment", "count"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("lock_timeouts", "lock", "counter", "Number of lock timeouts", 0).
+		AddRow("buffer_pool_size", "server", "value", "Server buffer pool size (all buffer pools) in bytes", 2)
+	mock.ExpectQuery(sanitizeQuery(infoSchemaInnodbMetricsQuery)).WithArgs("^(lock|server)$").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbMetrics{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	for range ch {
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Scrape `information_schema.innodb_sys_tablespaces`.
+// Scrape `information_schema.innodb_sys_tablespaces` or `information_schema.innodb_tablespaces`.
 
 package collector
 
@@ -34,6 +34,20 @@ const innodbTablespacesQuery = `
 	  FROM information_schema.innodb_sys_tablespaces
 	`
 
+// innodbTablespacesQueryMySQL80 reads the table renamed in MySQL 8.0, which
+// no longer has a FILE_FORMAT column.
+const innodbTablespacesQueryMySQL80 = `
+	SELECT
+	    SPACE,
+	    NAME,
+	    'NONE' as FILE_FORMAT,
+	    ifnull(ROW_FORMAT, 'NONE') as ROW_FORMAT,
+	    ifnull(SPACE_TYPE, 'NONE') as SPACE_TYPE,
+	    FILE_SIZE,
+	    ALLOCATED_SIZE
+	  FROM information_schema.innodb_tablespaces
+	`
+
 // Metric descriptors.
 var (
 	infoSchemaInnodbTablesspaceInfoDesc = prometheus.NewDesc(
@@ -53,7 +67,8 @@ var (
 	)
 )
 
-// ScrapeInfoSchemaInnodbTablespaces collects from `information_schema.innodb_sys_tablespaces`.
+// ScrapeInfoSchemaInnodbTablespaces collects from `information_schema.innodb_sys_tablespaces`,
+// called `information_schema.innodb_tablespaces` since MySQL 8.0.
 type ScrapeInfoSchemaInnodbTablespaces struct{}
 
 // Name of the Scraper. Should be unique.
@@ -63,7 +78,7 @@ func (ScrapeInfoSchemaInnodbTablespaces) Name() string {
 
 // Help describes the role of the Scraper.
 func (ScrapeInfoSchemaInnodbTablespaces) Help() string {
-	return "Collect metrics from information_schema.innodb_sys_tablespaces"
+	return "Collect metrics from information_schema.innodb_sys_tablespaces, or information_schema.innodb_tablespaces on MySQL 8.0"
 }
 
 // Version of MySQL from which scraper is available.
@@ -73,7 +88,12 @@ func (ScrapeInfoSchemaInnodbTablespaces) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	tablespacesRows, err := db.QueryContext(ctx, innodbTablespacesQuery)
+	query := innodbTablespacesQuery
+	// MariaDB reports versions above 8.0 but kept the old table name.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor != flavorMariaDB && v.atLeast(8, 0, 0) {
+		query = innodbTablespacesQueryMySQL80
+	}
+	tablespacesRows, err := queryContext(ctx, db, query)
 	if err != nil {
 		return err
 	}
@@ -100,6 +120,9 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 			&allocatedSize,
 		)
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -64,3 +64,44 @@ func TestScrapeInfoSchemaInnodbTablespaces(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeInfoSchemaInnodbTablespacesMySQL80(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"SPACE", "NAME", "FILE_FORMAT", "ROW_FORMAT", "SPACE_TYPE", "FILE_SIZE", "ALLOCATED_SIZE"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(4294967294, "mysql", "NONE", "Any", "General", 25165824, 25165824)
+	mock.ExpectQuery(sanitizeQuery(innodbTablespacesQueryMySQL80)).WillReturnRows(rows)
+
+	ctx := withServerVersion(context.Background(), parseServerVersion("8.0.33", "MySQL Community Server - GPL"))
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInfoSchemaInnodbTablespaces{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"tablespace_name": "mysql", "file_format": "NONE", "row_format": "Any", "space_type": "General"}, value: 4294967294, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"tablespace_name": "mysql"}, value: 25165824, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"tablespace_name": "mysql"}, value: 25165824, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, got)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
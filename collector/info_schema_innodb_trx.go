@@ -0,0 +1,204 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.innodb_trx`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// infoSchemaInnodbTrxQuery returns the server time along with each
+// transaction, so that ages do not depend on the clock of the exporter.
+const infoSchemaInnodbTrxQuery = `
+	SELECT trx_id, trx_state, trx_started, NOW(), trx_rows_locked, trx_rows_modified
+	  FROM information_schema.innodb_trx
+	  WHERE trx_started <= NOW() - INTERVAL ? SECOND
+	`
+
+// mysqlTimeLayout is the format of DATETIME values without parseTime.
+const mysqlTimeLayout = "2006-01-02 15:04:05"
+
+// Tunable flags.
+var (
+	innodbTrxMinAge = kingpin.Flag(
+		"collect.info_schema.innodb_trx.min_age",
+		"Minimum age in seconds of the transactions to collect",
+	).Default("0").Int()
+	innodbTrxThresholds = kingpin.Flag(
+		"collect.info_schema.innodb_trx.thresholds",
+		"Comma separated list of ages in seconds to count transactions older than",
+	).Default("10,60,300").String()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaInnodbTrxDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_trx"),
+		"The number of InnoDB transactions by state.",
+		[]string{"state"}, nil,
+	)
+	infoSchemaInnodbTrxOldestAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_trx_oldest_age_seconds"),
+		"The age of the oldest InnoDB transaction, 0 without transactions.",
+		nil, nil,
+	)
+	infoSchemaInnodbTrxOlderThanDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_trx_older_than"),
+		"The number of InnoDB transactions older than the given age.",
+		[]string{"age_seconds"}, nil,
+	)
+	infoSchemaInnodbTrxRowsLockedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_trx_rows_locked"),
+		"The approximate number of rows locked by InnoDB transactions.",
+		nil, nil,
+	)
+	infoSchemaInnodbTrxRowsModifiedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_trx_rows_modified"),
+		"The number of rows modified and inserted by InnoDB transactions.",
+		nil, nil,
+	)
+)
+
+// ScrapeInnodbTrx collects from `information_schema.innodb_trx`.
+type ScrapeInnodbTrx struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbTrx) Name() string {
+	return informationSchema + ".innodb_trx"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbTrx) Help() string {
+	return "Collect the age of long running transactions from information_schema.innodb_trx"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbTrx) Version() float64 {
+	return 5.5
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbTrx) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	thresholds, err := parseInnodbTrxThresholds(*innodbTrxThresholds)
+	if err != nil {
+		return err
+	}
+	trxRows, err := queryContext(ctx, db, infoSchemaInnodbTrxQuery, *innodbTrxMinAge)
+	if err != nil {
+		return err
+	}
+	defer trxRows.Close()
+
+	var (
+		trxID, state, started, now string
+		rowsLocked, rowsModified   uint64
+		totalLocked, totalModified uint64
+		oldest                     float64
+	)
+	states := map[string]int{}
+	var stateOrder []string
+	olderThan := make([]int, len(thresholds))
+	for trxRows.Next() {
+		if err := trxRows.Scan(&trxID, &state, &started, &now, &rowsLocked, &rowsModified); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		age, err := innodbTrxAge(started, now)
+		if err != nil {
+			return err
+		}
+		if age > oldest {
+			oldest = age
+		}
+		for i, threshold := range thresholds {
+			if age > threshold {
+				olderThan[i]++
+			}
+		}
+		if _, ok := states[state]; !ok {
+			stateOrder = append(stateOrder, state)
+		}
+		states[state]++
+		totalLocked += rowsLocked
+		totalModified += rowsModified
+	}
+	if err := trxRows.Err(); err != nil {
+		return err
+	}
+
+	for _, state := range stateOrder {
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbTrxDesc, prometheus.GaugeValue, float64(states[state]), state)
+	}
+	ch <- prometheus.MustNewConstMetric(infoSchemaInnodbTrxOldestAgeDesc, prometheus.GaugeValue, oldest)
+	for i, threshold := range thresholds {
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbTrxOlderThanDesc, prometheus.GaugeValue, float64(olderThan[i]),
+			strconv.FormatFloat(threshold, 'f', -1, 64))
+	}
+	ch <- prometheus.MustNewConstMetric(infoSchemaInnodbTrxRowsLockedDesc, prometheus.GaugeValue, float64(totalLocked))
+	ch <- prometheus.MustNewConstMetric(infoSchemaInnodbTrxRowsModifiedDesc, prometheus.GaugeValue, float64(totalModified))
+	return nil
+}
+
+// innodbTrxAge returns the seconds between the trx_started and NOW() values.
+func innodbTrxAge(started, now string) (float64, error) {
+	startedTime, err := parseMySQLTime(started)
+	if err != nil {
+		return 0, err
+	}
+	nowTime, err := parseMySQLTime(now)
+	if err != nil {
+		return 0, err
+	}
+	return nowTime.Sub(startedTime).Seconds(), nil
+}
+
+// parseMySQLTime parses a DATETIME value, as returned with and without the
+// parseTime DSN parameter.
+func parseMySQLTime(value string) (time.Time, error) {
+	if t, err := time.Parse(mysqlTimeLayout, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// parseInnodbTrxThresholds parses a comma separated list of ages in seconds.
+func parseInnodbTrxThresholds(list string) ([]float64, error) {
+	var thresholds []float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid innodb_trx threshold %q: %s", field, err)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbTrx{}
@@ -0,0 +1,95 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeInnodbTrx(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.innodb_trx.min_age", "5",
+		"--collect.info_schema.innodb_trx.thresholds", "10,60",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"trx_id", "trx_state", "trx_started", "NOW()", "trx_rows_locked", "trx_rows_modified"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("3934", "RUNNING", "2019-07-10 11:58:00", "2019-07-10 12:00:00", "10", "2").
+		AddRow("3935", "LOCK WAIT", "2019-07-10 11:59:30", "2019-07-10 12:00:00", "1", "0").
+		AddRow("3936", "RUNNING", "2019-07-10 11:59:55", "2019-07-10 12:00:00", "0", "0")
+	mock.ExpectQuery(sanitizeQuery(infoSchemaInnodbTrxQuery)).WithArgs(5).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbTrx{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"state": "RUNNING"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "LOCK WAIT"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 120, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"age_seconds": "10"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"age_seconds": "60"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 11, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestInnodbTrxAge(t *testing.T) {
+	convey.Convey("Transaction age", t, func() {
+		age, err := innodbTrxAge("2019-07-10 23:59:59", "2019-07-11 00:00:09")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(age, convey.ShouldEqual, 10)
+
+		age, err = innodbTrxAge("2019-07-10T11:00:00Z", "2019-07-10T12:00:00Z")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(age, convey.ShouldEqual, 3600)
+
+		_, err = innodbTrxAge("yesterday", "2019-07-10 12:00:00")
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
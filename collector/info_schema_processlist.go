@@ -32,7 +32,8 @@ const infoSchemaProcesslistQuery = `
 		    COALESCE(command,'') AS command,
 		    COALESCE(state,'') AS state,
 		    count(*) AS processes,
-		    sum(time) AS seconds
+		    sum(time) AS seconds,
+		    max(time) AS max_seconds
 		  FROM information_schema.processlist
 		  WHERE ID != connection_id()
 		    AND TIME >= %d
@@ -66,6 +67,10 @@ var (
 		prometheus.BuildFQName(namespace, informationSchema, "threads_seconds"),
 		"The number of seconds threads (connections) have used split by current state.",
 		[]string{"state"}, nil)
+	processlistMaxTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "threads_max_seconds"),
+		"The number of seconds the oldest thread (connection) has been in its current state, split by current state.",
+		[]string{"state"}, nil)
 	processesByUserDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, informationSchema, "processes_by_user"),
 		"The number of processes by user.",
@@ -181,7 +186,7 @@ func (ScrapeProcesslist) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		infoSchemaProcesslistQuery,
 		*processlistMinTime,
 	)
-	processlistRows, err := db.QueryContext(ctx, processQuery)
+	processlistRows, err := queryContext(ctx, db, processQuery)
 	if err != nil {
 		return err
 	}
@@ -194,24 +199,33 @@ func (ScrapeProcesslist) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		state     string
 		processes uint32
 		time      uint32
+		maxTime   uint32
 	)
 	stateCounts := make(map[string]uint32, len(threadStateCounterMap))
 	stateTime := make(map[string]uint32, len(threadStateCounterMap))
+	stateMaxTime := make(map[string]uint32, len(threadStateCounterMap))
 	hostCount := make(map[string]uint32)
 	userCount := make(map[string]uint32)
 	for k, v := range threadStateCounterMap {
 		stateCounts[k] = v
 		stateTime[k] = v
+		stateMaxTime[k] = v
 	}
 
 	for processlistRows.Next() {
-		err = processlistRows.Scan(&user, &host, &command, &state, &processes, &time)
+		err = processlistRows.Scan(&user, &host, &command, &state, &processes, &time, &maxTime)
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		realState := deriveThreadState(command, state)
 		stateCounts[realState] += processes
 		stateTime[realState] += time
+		if maxTime > stateMaxTime[realState] {
+			stateMaxTime[realState] = maxTime
+		}
 		hostCount[host] = hostCount[host] + processes
 		userCount[user] = userCount[user] + processes
 	}
@@ -234,6 +248,9 @@ func (ScrapeProcesslist) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 	for state, time := range stateTime {
 		ch <- prometheus.MustNewConstMetric(processlistTimeDesc, prometheus.GaugeValue, float64(time), state)
 	}
+	for state, maxTime := range stateMaxTime {
+		ch <- prometheus.MustNewConstMetric(processlistMaxTimeDesc, prometheus.GaugeValue, float64(maxTime), state)
+	}
 
 	return nil
 }
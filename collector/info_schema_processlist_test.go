@@ -0,0 +1,67 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeProcesslist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "host", "command", "state", "processes", "seconds", "max_seconds"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "10.0.0.1", "Query", "Sending data", "3", "90", "60").
+		AddRow("app", "10.0.0.2", "Query", "Sending data", "1", "80", "80").
+		AddRow("app", "10.0.0.1", "Sleep", "", "5", "10", "4")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(infoSchemaProcesslistQuery, 0))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeProcesslist{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	// The states are sent in map order, so index the metrics by state.
+	maxTimes := map[string]float64{}
+	for m := range ch {
+		if m.Desc() != processlistMaxTimeDesc {
+			continue
+		}
+		got := readMetric(m)
+		maxTimes[got.labels["state"]] = got.value
+	}
+	convey.Convey("Max thread time per state", t, func() {
+		convey.So(maxTimes["sending data"], convey.ShouldEqual, 80)
+		convey.So(maxTimes["idle"], convey.ShouldEqual, 4)
+		convey.So(maxTimes["statistics"], convey.ShouldEqual, 0)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -56,7 +56,7 @@ var (
 )
 
 func processQueryResponseTimeTable(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, query string, i int) error {
-	queryDistributionRows, err := db.QueryContext(ctx, query)
+	queryDistributionRows, err := queryContext(ctx, db, query)
 	if err != nil {
 		return err
 	}
@@ -78,6 +78,9 @@ func processQueryResponseTimeTable(ctx context.Context, db *sql.DB, ch chan<- pr
 			&total,
 		)
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
@@ -120,7 +123,7 @@ func (ScrapeQueryResponseTime) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeQueryResponseTime) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	var queryStats uint8
-	err := db.QueryRowContext(ctx, queryResponseCheckQuery).Scan(&queryStats)
+	err := queryRowContext(ctx, db, queryResponseCheckQuery).Scan(&queryStats)
 	if err != nil {
 		log.Debugln("Query response time distribution is not present.")
 		return nil
@@ -74,7 +74,7 @@ func (ScrapeSchemaStat) Version() float64 {
 func (ScrapeSchemaStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	var varName, varVal string
 
-	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
+	err := queryRowContext(ctx, db, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		log.Debugln("Detailed schema stats are not available.")
 		return nil
@@ -84,7 +84,7 @@ func (ScrapeSchemaStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		return nil
 	}
 
-	informationSchemaTableStatisticsRows, err := db.QueryContext(ctx, schemaStatQuery)
+	informationSchemaTableStatisticsRows, err := queryContext(ctx, db, schemaStatQuery)
 	if err != nil {
 		return err
 	}
@@ -106,6 +106,9 @@ func (ScrapeSchemaStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		)
 
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
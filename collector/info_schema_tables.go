@@ -46,7 +46,9 @@ const (
 		SELECT
 		    SCHEMA_NAME
 		  FROM information_schema.schemata
-		  WHERE SCHEMA_NAME NOT IN ('mysql', 'performance_schema', 'information_schema')
+		  WHERE SCHEMA_NAME NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+		    AND SCHEMA_NAME REGEXP ?
+		    AND SCHEMA_NAME NOT REGEXP ?
 		`
 )
 
@@ -56,6 +58,14 @@ var (
 		"collect.info_schema.tables.databases",
 		"The list of databases to collect table stats for, or '*' for all",
 	).Default("*").String()
+	tableSchemaInclude = kingpin.Flag(
+		"collect.info_schema.tables.schema_include",
+		"RegEx of databases to collect table stats for when collecting all databases",
+	).Default(".*").String()
+	tableSchemaExclude = kingpin.Flag(
+		"collect.info_schema.tables.schema_exclude",
+		"RegEx of databases to skip when collecting all databases",
+	).Default("^$").String()
 )
 
 // Metric descriptors.
@@ -99,7 +109,7 @@ func (ScrapeTableSchema) Version() float64 {
 func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	var dbList []string
 	if *tableSchemaDatabases == "*" {
-		dbListRows, err := db.QueryContext(ctx, dbListQuery)
+		dbListRows, err := queryContext(ctx, db, dbListQuery, *tableSchemaInclude, *tableSchemaExclude)
 		if err != nil {
 			return err
 		}
@@ -111,6 +121,9 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 			if err := dbListRows.Scan(
 				&database,
 			); err != nil {
+				if skipScanError(ctx, err) {
+					continue
+				}
 				return err
 			}
 			dbList = append(dbList, database)
@@ -120,7 +133,7 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 	}
 
 	for _, database := range dbList {
-		tableSchemaRows, err := db.QueryContext(ctx, fmt.Sprintf(tableSchemaQuery, database))
+		tableSchemaRows, err := queryContext(ctx, db, fmt.Sprintf(tableSchemaQuery, database))
 		if err != nil {
 			return err
 		}
@@ -155,6 +168,9 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 				&createOptions,
 			)
 			if err != nil {
+				if skipScanError(ctx, err) {
+					continue
+				}
 				return err
 			}
 			ch <- prometheus.MustNewConstMetric(
@@ -21,6 +21,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/log"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const tableStatQuery = `
@@ -31,8 +32,22 @@ const tableStatQuery = `
 		  ROWS_CHANGED,
 		  ROWS_CHANGED_X_INDEXES
 		  FROM information_schema.table_statistics
+		  WHERE TABLE_SCHEMA REGEXP ?
+		    AND TABLE_NAME REGEXP ?
 		`
 
+// Tunable flags.
+var (
+	tableStatSchemaInclude = kingpin.Flag(
+		"collect.info_schema.tablestats.schema_include",
+		"RegEx of schemas to collect table statistics for",
+	).Default(".*").String()
+	tableStatTableInclude = kingpin.Flag(
+		"collect.info_schema.tablestats.table_include",
+		"RegEx of tables to collect table statistics for",
+	).Default(".*").String()
+)
+
 // Metric descriptors.
 var (
 	infoSchemaTableStatsRowsReadDesc = prometheus.NewDesc(
@@ -72,8 +87,11 @@ func (ScrapeTableStat) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !userstatSupported(ctx) {
+		return nil
+	}
 	var varName, varVal string
-	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
+	err := queryRowContext(ctx, db, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		log.Debugln("Detailed table stats are not available.")
 		return nil
@@ -83,7 +101,7 @@ func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 		return nil
 	}
 
-	informationSchemaTableStatisticsRows, err := db.QueryContext(ctx, tableStatQuery)
+	informationSchemaTableStatisticsRows, err := queryContext(ctx, db, tableStatQuery, *tableStatSchemaInclude, *tableStatTableInclude)
 	if err != nil {
 		return err
 	}
@@ -106,6 +124,9 @@ func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 			&rowsChangedXIndexes,
 		)
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -121,7 +142,7 @@ func (ScrapeTableStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometh
 			tableSchema, tableName,
 		)
 	}
-	return nil
+	return informationSchemaTableStatisticsRows.Err()
 }
 
 // check interface
@@ -15,14 +15,21 @@ package collector
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 func TestScrapeTableStat(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("error opening a stub database connection: %s", err)
@@ -37,7 +44,7 @@ func TestScrapeTableStat(t *testing.T) {
 		AddRow("mysql", "db", 238, 0, 8).
 		AddRow("mysql", "proxies_priv", 99, 1, 0).
 		AddRow("mysql", "user", 1064, 2, 5)
-	mock.ExpectQuery(sanitizeQuery(tableStatQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(tableStatQuery)).WithArgs(".*", ".*").WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -70,3 +77,126 @@ func TestScrapeTableStat(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeTableStatFilters(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.info_schema.tablestats.schema_include", "^app$",
+		"--collect.info_schema.tablestats.table_include", "^orders",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(userstatCheckQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+		AddRow("userstat", "ON"))
+	columns := []string{"TABLE_SCHEMA", "TABLE_NAME", "ROWS_READ", "ROWS_CHANGED", "ROWS_CHANGED_X_INDEXES"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "orders", 10, 2, 4)
+	mock.ExpectQuery(sanitizeQuery(tableStatQuery)).WithArgs("^app$", "^orders").WillReturnRows(rows)
+
+	ctx := withServerVersion(context.Background(), parseServerVersion("8.0.33-25", "Percona Server (GPL), Release 25"))
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeTableStat{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "app", "table": "orders"}, value: 10},
+		{labels: labelMap{"schema": "app", "table": "orders"}, value: 2},
+		{labels: labelMap{"schema": "app", "table": "orders"}, value: 4},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, got)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeTableStatOracleMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	ctx := withServerVersion(context.Background(), parseServerVersion("8.0.33", "MySQL Community Server - GPL"))
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeTableStat{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics on Oracle MySQL", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure no SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// BenchmarkScrapeTableStat measures the scrape of a large table_statistics
+// result set, the kind of scrape whose time depends on the network.
+func BenchmarkScrapeTableStat(b *testing.B) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	columns := []string{"TABLE_SCHEMA", "TABLE_NAME", "ROWS_READ", "ROWS_CHANGED", "ROWS_CHANGED_X_INDEXES"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			b.Fatalf("error opening a stub database connection: %s", err)
+		}
+		mock.ExpectQuery(sanitizeQuery(userstatCheckQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("userstat", "ON"))
+		rows := sqlmock.NewRows(columns)
+		for table := 0; table < 10000; table++ {
+			rows.AddRow("shop", "table_"+strconv.Itoa(table), 1000000, 1000, 100)
+		}
+		mock.ExpectQuery(sanitizeQuery(tableStatQuery)).WithArgs(".*", ".*").WillReturnRows(rows)
+		ch := make(chan prometheus.Metric, 64)
+		done := make(chan struct{})
+		go func() {
+			for range ch {
+			}
+			close(done)
+		}()
+		b.StartTimer()
+
+		if err := (ScrapeTableStat{}).Scrape(context.Background(), db, ch); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		close(ch)
+		<-done
+		db.Close()
+		b.StartTimer()
+	}
+}
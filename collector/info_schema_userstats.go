@@ -157,8 +157,11 @@ func (ScrapeUserStat) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeUserStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !userstatSupported(ctx) {
+		return nil
+	}
 	var varName, varVal string
-	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
+	err := queryRowContext(ctx, db, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		log.Debugln("Detailed user stats are not available.")
 		return nil
@@ -168,7 +171,7 @@ func (ScrapeUserStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promethe
 		return nil
 	}
 
-	informationSchemaUserStatisticsRows, err := db.QueryContext(ctx, userStatQuery)
+	informationSchemaUserStatisticsRows, err := queryContext(ctx, db, userStatQuery)
 	if err != nil {
 		return err
 	}
@@ -195,6 +198,9 @@ func (ScrapeUserStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promethe
 	for informationSchemaUserStatisticsRows.Next() {
 		err = informationSchemaUserStatisticsRows.Scan(userStatScanArgs...)
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
@@ -211,7 +217,7 @@ func (ScrapeUserStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promethe
 			}
 		}
 	}
-	return nil
+	return informationSchemaUserStatisticsRows.Err()
 }
 
 // check interface
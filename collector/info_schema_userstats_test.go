@@ -40,7 +40,7 @@ func TestScrapeUserStat(t *testing.T) {
 
 	ch := make(chan prometheus.Metric)
 	go func() {
-		if err = (ScrapeUserStat{}).Scrape(context.Background(), db, ch); err != nil {
+		if err = (ScrapeUserStat{}).Scrape(withServerVersion(context.Background(), parseServerVersion("5.7.40-43", "Percona Server (GPL), Release 43")), db, ch); err != nil {
 			t.Errorf("error calling function on test: %s", err)
 		}
 		close(ch)
@@ -82,3 +82,45 @@ func TestScrapeUserStat(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeUserStatUnavailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		comment string
+		query   bool
+	}{
+		{name: "Oracle MySQL", version: "8.0.33", comment: "MySQL Community Server - GPL"},
+		{name: "Percona without userstat", version: "5.7.40-43", comment: "Percona Server (GPL), Release 43", query: true},
+	}
+	for _, tt := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		if tt.query {
+			mock.ExpectQuery(sanitizeQuery(userstatCheckQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+		}
+
+		ctx := withServerVersion(context.Background(), parseServerVersion(tt.version, tt.comment))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeUserStat{}).Scrape(ctx, db, ch); err != nil {
+				t.Errorf("%s: error calling function on test: %s", tt.name, err)
+			}
+			close(ch)
+		}()
+
+		convey.Convey("No metrics on "+tt.name, t, func() {
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: there were unfulfilled exceptions: %s", tt.name, err)
+		}
+		db.Close()
+	}
+}
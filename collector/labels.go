@@ -0,0 +1,47 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import "sync"
+
+// labelCacheSize bounds the number of values kept by a labelCache.
+const labelCacheSize = 16384
+
+// labelCache interns label values scanned as sql.RawBytes. Collectors with a
+// label per account would otherwise allocate the same strings for every row
+// of every scrape. The cache is cleared once it holds labelCacheSize values,
+// so that values of dropped accounts do not pile up.
+type labelCache struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newLabelCache() *labelCache {
+	return &labelCache{values: map[string]string{}}
+}
+
+// intern returns the string of b, allocating it only on the first lookup.
+func (c *labelCache) intern(b []byte) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.values[string(b)]; ok {
+		return v
+	}
+	if len(c.values) >= labelCacheSize {
+		c.values = map[string]string{}
+	}
+	v := string(b)
+	c.values[v] = v
+	return v
+}
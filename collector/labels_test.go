@@ -0,0 +1,79 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestLabelCache(t *testing.T) {
+	convey.Convey("Label values are interned", t, func() {
+		c := newLabelCache()
+		first := c.intern([]byte("app"))
+		convey.So(first, convey.ShouldEqual, "app")
+		convey.So(c.intern([]byte("app")), convey.ShouldEqual, "app")
+		convey.So(c.intern([]byte("")), convey.ShouldEqual, "")
+		convey.So(c.values, convey.ShouldHaveLength, 2)
+
+		allocs := testing.AllocsPerRun(100, func() { c.intern([]byte("app")) })
+		convey.So(allocs, convey.ShouldEqual, 0)
+	})
+	convey.Convey("The cache is cleared when full", t, func() {
+		c := newLabelCache()
+		for i := 0; i < labelCacheSize; i++ {
+			c.intern([]byte(fmt.Sprintf("user%d", i)))
+		}
+		convey.So(c.values, convey.ShouldHaveLength, labelCacheSize)
+		convey.So(c.intern([]byte("app")), convey.ShouldEqual, "app")
+		convey.So(c.values, convey.ShouldHaveLength, 1)
+	})
+}
+
+// benchmarkUsers returns the user column of a server with thousands of
+// accounts, as scanned into sql.RawBytes.
+func benchmarkUsers() [][]byte {
+	users := make([][]byte, 5000)
+	for i := range users {
+		users[i] = []byte(fmt.Sprintf("app_user_%d", i))
+	}
+	return users
+}
+
+// BenchmarkLabelValues compares converting the user column of every row to a
+// string with interning it, over repeated scrapes.
+func BenchmarkLabelValues(b *testing.B) {
+	users := benchmarkUsers()
+	var label string
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, user := range users {
+				label = string(user)
+			}
+		}
+	})
+	b.Run("intern", func(b *testing.B) {
+		c := newLabelCache()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, user := range users {
+				label = c.intern(user)
+			}
+		}
+	})
+	_ = label
+}
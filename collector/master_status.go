@@ -0,0 +1,122 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW MASTER STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	master = "master"
+	// Queries.
+	masterStatusQuery = `SHOW MASTER STATUS`
+	// binaryLogStatusQuery replaces SHOW MASTER STATUS as of MySQL 8.2.0.
+	binaryLogStatusQuery = `SHOW BINARY LOG STATUS`
+)
+
+// Metric descriptors.
+var (
+	masterBinlogPositionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, master, "binlog_position"),
+		"Position in the current binlog file the master writes to.",
+		[]string{}, nil,
+	)
+	masterBinlogInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, master, "binlog_info"),
+		"Current binlog file and executed GTID set of the master.",
+		[]string{"binlog_file", "executed_gtid_set"}, nil,
+	)
+)
+
+// ScrapeMasterStatus collects from `SHOW MASTER STATUS`.
+type ScrapeMasterStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeMasterStatus) Name() string {
+	return "master_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeMasterStatus) Help() string {
+	return "Collect the current binlog file and position from SHOW MASTER STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeMasterStatus) Version() float64 {
+	return 5.5
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeMasterStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	query := masterStatusQuery
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor != flavorMariaDB && v.atLeast(8, 2, 0) {
+		query = binaryLogStatusQuery
+	}
+	masterStatusRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return err
+	}
+	defer masterStatusRows.Close()
+
+	// Binary logging disabled returns an empty result.
+	if !masterStatusRows.Next() {
+		return masterStatusRows.Err()
+	}
+
+	// Executed_Gtid_Set only exists as of MySQL 5.6, so scan by column name.
+	columnNames, err := masterStatusRows.Columns()
+	if err != nil {
+		return err
+	}
+	scanArgs := make([]interface{}, len(columnNames))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+	if err := masterStatusRows.Scan(scanArgs...); err != nil {
+		return err
+	}
+
+	var file, position, gtidSet string
+	for i, col := range columnNames {
+		value := string(*scanArgs[i].(*sql.RawBytes))
+		switch strings.ToLower(col) {
+		case "file":
+			file = value
+		case "position":
+			position = value
+		case "executed_gtid_set":
+			// Multi-line GTID sets are separated by ",\n".
+			gtidSet = strings.Replace(value, "\n", "", -1)
+		}
+	}
+
+	pos, err := strconv.ParseFloat(position, 64)
+	if err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(masterBinlogPositionDesc, prometheus.GaugeValue, pos)
+	ch <- prometheus.MustNewConstMetric(masterBinlogInfoDesc, prometheus.GaugeValue, 1, file, gtidSet)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeMasterStatus{}
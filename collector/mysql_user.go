@@ -122,7 +122,7 @@ func (ScrapeUser) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.M
 		err      error
 	)
 	userQuery := fmt.Sprint(mysqlUserQuery)
-	userRows, err = db.QueryContext(ctx, userQuery)
+	userRows, err = queryContext(ctx, db, userQuery)
 	if err != nil {
 		return err
 	}
@@ -221,6 +221,9 @@ func (ScrapeUser) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.M
 			}
 
 			if err := userRows.Scan(scanArgs...); err != nil {
+				if skipScanError(ctx, err) {
+					continue
+				}
 				return err
 			}
 
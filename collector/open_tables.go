@@ -0,0 +1,153 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW OPEN TABLES`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	// Subsystem.
+	openTables = "open_tables"
+	// Query.
+	openTablesQuery = `SHOW OPEN TABLES`
+)
+
+// Tunable flags.
+var (
+	openTablesDatabaseFilter = kingpin.Flag(
+		"collect.open_tables.database_filter",
+		"RegEx filter for the databases of the open tables to count",
+	).Default(".*").String()
+	openTablesByDatabase = kingpin.Flag(
+		"collect.open_tables.by_database",
+		"Additionally collect the number of open tables per database",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	openTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", openTables),
+		"The number of tables open in the table cache.",
+		nil, nil,
+	)
+	openTablesInUseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, openTables, "in_use"),
+		"The number of open tables locked or in use by a statement.",
+		nil, nil,
+	)
+	openTablesByDatabaseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, openTables, "by_database"),
+		"The number of tables open in the table cache by database.",
+		[]string{"database"}, nil,
+	)
+	openTablesInUseByDatabaseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, openTables, "in_use_by_database"),
+		"The number of open tables locked or in use by a statement by database.",
+		[]string{"database"}, nil,
+	)
+)
+
+// openTablesCount holds the number of open and in use tables of a database.
+type openTablesCount struct {
+	open, inUse int
+}
+
+// ScrapeOpenTables collects from `SHOW OPEN TABLES`.
+type ScrapeOpenTables struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeOpenTables) Name() string {
+	return openTables
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeOpenTables) Help() string {
+	return "Collect the number of open and in use tables from SHOW OPEN TABLES"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeOpenTables) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeOpenTables) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	filter, err := regexp.Compile(*openTablesDatabaseFilter)
+	if err != nil {
+		return err
+	}
+	openTablesRows, err := queryContext(ctx, db, openTablesQuery)
+	if err != nil {
+		return err
+	}
+	defer openTablesRows.Close()
+
+	var (
+		database, table   string
+		inUse, nameLocked uint64
+		total             openTablesCount
+		databaseOrder     []string
+	)
+	byDatabase := map[string]*openTablesCount{}
+	for openTablesRows.Next() {
+		if err := openTablesRows.Scan(&database, &table, &inUse, &nameLocked); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if !filter.MatchString(database) {
+			continue
+		}
+		count, ok := byDatabase[database]
+		if !ok {
+			count = &openTablesCount{}
+			byDatabase[database] = count
+			databaseOrder = append(databaseOrder, database)
+		}
+		count.open++
+		total.open++
+		// In_use is the number of table locks or lock requests.
+		if inUse > 0 {
+			count.inUse++
+			total.inUse++
+		}
+	}
+	if err := openTablesRows.Err(); err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(openTablesDesc, prometheus.GaugeValue, float64(total.open))
+	ch <- prometheus.MustNewConstMetric(openTablesInUseDesc, prometheus.GaugeValue, float64(total.inUse))
+	if *openTablesByDatabase {
+		for _, database := range databaseOrder {
+			count := byDatabase[database]
+			ch <- prometheus.MustNewConstMetric(openTablesByDatabaseDesc, prometheus.GaugeValue, float64(count.open), database)
+			ch <- prometheus.MustNewConstMetric(openTablesInUseByDatabaseDesc, prometheus.GaugeValue, float64(count.inUse), database)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeOpenTables{}
@@ -0,0 +1,136 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.data_locks`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const perfDataLocksQuery = `
+	SELECT OBJECT_SCHEMA, OBJECT_NAME, LOCK_TYPE, LOCK_STATUS, COUNT(*)
+	  FROM performance_schema.data_locks
+	  WHERE OBJECT_SCHEMA IS NOT NULL
+	  GROUP BY OBJECT_SCHEMA, OBJECT_NAME, LOCK_TYPE, LOCK_STATUS
+	`
+
+// Tunable flags.
+var (
+	perfDataLocksLimit = kingpin.Flag(
+		"collect.perf_schema.data_locks.limit",
+		"Limit the number of tables by number of locks, 0 for no limit",
+	).Default("0").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaDataLocksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "data_locks"),
+		"The number of data locks held or waited for by table, lock type and status.",
+		[]string{"schema", "name", "lock_type", "lock_status"}, nil,
+	)
+)
+
+// perfDataLocksTable holds the lock counts of a table.
+type perfDataLocksTable struct {
+	schema, name string
+	total        uint64
+	counts       []perfDataLocksCount
+}
+
+type perfDataLocksCount struct {
+	lockType, lockStatus string
+	count                uint64
+}
+
+// ScrapeDataLocks collects from `performance_schema.data_locks`.
+type ScrapeDataLocks struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeDataLocks) Name() string {
+	return "perf_schema.data_locks"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeDataLocks) Help() string {
+	return "Collect granted and waiting lock counts per table from performance_schema.data_locks"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeDataLocks) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeDataLocks) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB reports versions above 8.0 but has no data_locks table.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	dataLocksRows, err := queryContext(ctx, db, perfDataLocksQuery)
+	if err != nil {
+		return err
+	}
+	defer dataLocksRows.Close()
+
+	var (
+		schema, name, lockType, lockStatus string
+		count                              uint64
+	)
+	var tables []*perfDataLocksTable
+	byTable := map[[2]string]*perfDataLocksTable{}
+	for dataLocksRows.Next() {
+		if err := dataLocksRows.Scan(&schema, &name, &lockType, &lockStatus, &count); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		table, ok := byTable[[2]string{schema, name}]
+		if !ok {
+			table = &perfDataLocksTable{schema: schema, name: name}
+			byTable[[2]string{schema, name}] = table
+			tables = append(tables, table)
+		}
+		table.total += count
+		table.counts = append(table.counts, perfDataLocksCount{lockType, lockStatus, count})
+	}
+	if err := dataLocksRows.Err(); err != nil {
+		return err
+	}
+
+	// Keep the tables with the most locks.
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].total > tables[j].total })
+	if *perfDataLocksLimit > 0 && len(tables) > *perfDataLocksLimit {
+		tables = tables[:*perfDataLocksLimit]
+	}
+	for _, table := range tables {
+		for _, c := range table.counts {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaDataLocksDesc, prometheus.GaugeValue, float64(c.count),
+				table.schema, table.name, c.lockType, c.lockStatus,
+			)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeDataLocks{}
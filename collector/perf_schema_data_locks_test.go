@@ -0,0 +1,78 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeDataLocks(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.data_locks.limit", "2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"OBJECT_SCHEMA", "OBJECT_NAME", "LOCK_TYPE", "LOCK_STATUS", "COUNT(*)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", "carts", "TABLE", "GRANTED", "1").
+		AddRow("shop", "orders", "RECORD", "GRANTED", "12").
+		AddRow("shop", "orders", "RECORD", "WAITING", "3").
+		AddRow("shop", "orders", "TABLE", "GRANTED", "2").
+		AddRow("shop", "stock", "RECORD", "WAITING", "4")
+	mock.ExpectQuery(sanitizeQuery(perfDataLocksQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeDataLocks{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "name": "orders", "lock_type": "RECORD", "lock_status": "GRANTED"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "name": "orders", "lock_type": "RECORD", "lock_status": "WAITING"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "name": "orders", "lock_type": "TABLE", "lock_status": "GRANTED"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop", "name": "stock", "lock_type": "RECORD", "lock_status": "WAITING"}, value: 4, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Locks are counted per table and only the busiest tables kept", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_errors_summary_global_by_error`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// perfEventsErrorsQuery returns the server time along with each error, so that
+// the recency filter does not depend on the clock of the exporter.
+const perfEventsErrorsQuery = `
+	SELECT ERROR_NAME, SUM_ERROR_RAISED, SUM_ERROR_HANDLED, LAST_SEEN, NOW()
+	  FROM performance_schema.events_errors_summary_global_by_error
+	  WHERE SUM_ERROR_RAISED > 0
+	    AND ERROR_NAME IS NOT NULL
+	`
+
+// Tunable flags.
+var (
+	perfEventsErrorsTimeLimit = kingpin.Flag(
+		"collect.perf_schema.eventserrors.timelimit",
+		"Only collect errors last raised within this many minutes, 0 for no limit",
+	).Default("0").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaErrorsRaisedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "errors_raised_total"),
+		"The number of times the error was raised.",
+		[]string{"error_name"}, nil,
+	)
+	performanceSchemaErrorsHandledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "errors_handled_total"),
+		"The number of times the error was handled by an SQL exception handler.",
+		[]string{"error_name"}, nil,
+	)
+)
+
+// ScrapeErrorsSummary collects from `performance_schema.events_errors_summary_global_by_error`.
+type ScrapeErrorsSummary struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeErrorsSummary) Name() string {
+	return "perf_schema.eventserrors"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeErrorsSummary) Help() string {
+	return "Collect the number of raised and handled errors by error name from performance_schema.events_errors_summary_global_by_error"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeErrorsSummary) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeErrorsSummary) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB reports versions above 8.0 but has no error summary tables.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	errorsRows, err := queryContext(ctx, db, perfEventsErrorsQuery)
+	if err != nil {
+		return err
+	}
+	defer errorsRows.Close()
+
+	timeLimit := time.Duration(*perfEventsErrorsTimeLimit) * time.Minute
+	var (
+		name            string
+		raised, handled uint64
+		lastSeen, now   string
+	)
+	for errorsRows.Next() {
+		if err := errorsRows.Scan(&name, &raised, &handled, &lastSeen, &now); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if timeLimit > 0 {
+			recent, err := perfEventsErrorSeenWithin(lastSeen, now, timeLimit)
+			if err != nil {
+				return err
+			}
+			if !recent {
+				continue
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(performanceSchemaErrorsRaisedDesc, prometheus.CounterValue, float64(raised), name)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaErrorsHandledDesc, prometheus.CounterValue, float64(handled), name)
+	}
+	return errorsRows.Err()
+}
+
+// perfEventsErrorSeenWithin reports whether the LAST_SEEN value is at most
+// limit before the NOW() value.
+func perfEventsErrorSeenWithin(lastSeen, now string, limit time.Duration) (bool, error) {
+	lastSeenTime, err := parseMySQLTime(lastSeen)
+	if err != nil {
+		return false, err
+	}
+	nowTime, err := parseMySQLTime(now)
+	if err != nil {
+		return false, err
+	}
+	return nowTime.Sub(lastSeenTime) <= limit, nil
+}
+
+// check interface
+var _ Scraper = ScrapeErrorsSummary{}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func perfEventsErrorsRows() *sqlmock.Rows {
+	columns := []string{"ERROR_NAME", "SUM_ERROR_RAISED", "SUM_ERROR_HANDLED", "LAST_SEEN", "NOW()"}
+	return sqlmock.NewRows(columns).
+		AddRow("ER_LOCK_WAIT_TIMEOUT", "37", "2", "2019-07-10 11:55:00", "2019-07-10 12:00:00").
+		AddRow("ER_DUP_ENTRY", "1204", "1200", "2019-07-10 11:59:59", "2019-07-10 12:00:00").
+		AddRow("ER_NO_SUCH_TABLE", "3", "0", "2019-07-09 08:12:00", "2019-07-10 12:00:00")
+}
+
+func TestScrapeErrorsSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfEventsErrorsQuery)).WillReturnRows(perfEventsErrorsRows())
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeErrorsSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"error_name": "ER_LOCK_WAIT_TIMEOUT"}, value: 37, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_LOCK_WAIT_TIMEOUT"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_DUP_ENTRY"}, value: 1204, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_DUP_ENTRY"}, value: 1200, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_NO_SUCH_TABLE"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_NO_SUCH_TABLE"}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeErrorsSummaryTimeLimit(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.perf_schema.eventserrors.timelimit", "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfEventsErrorsQuery)).WillReturnRows(perfEventsErrorsRows())
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeErrorsSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	// Errors last seen exactly as well as less than 5 minutes ago are kept.
+	metricExpected := []MetricResult{
+		{labels: labelMap{"error_name": "ER_LOCK_WAIT_TIMEOUT"}, value: 37, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_LOCK_WAIT_TIMEOUT"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_DUP_ENTRY"}, value: 1204, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error_name": "ER_DUP_ENTRY"}, value: 1200, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPerfEventsErrorSeenWithin(t *testing.T) {
+	convey.Convey("Recency of errors", t, func() {
+		recent, err := perfEventsErrorSeenWithin("2019-07-10 11:59:00", "2019-07-10 12:00:00", time.Minute)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(recent, convey.ShouldBeTrue)
+
+		recent, err = perfEventsErrorSeenWithin("2019-07-10 11:58:59", "2019-07-10 12:00:00", time.Minute)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(recent, convey.ShouldBeFalse)
+
+		// parseTime=true returns the times as RFC 3339.
+		recent, err = perfEventsErrorSeenWithin("2019-07-10T11:59:30Z", "2019-07-10T12:00:00Z", time.Minute)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(recent, convey.ShouldBeTrue)
+
+		_, err = perfEventsErrorSeenWithin("yesterday", "2019-07-10 12:00:00", time.Minute)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
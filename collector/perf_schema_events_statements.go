@@ -175,7 +175,7 @@ func (ScrapePerfEventsStatements) Scrape(ctx context.Context, db *sql.DB, ch cha
 		*perfEventsStatementsLimit,
 	)
 	// Timers here are returned in picoseconds.
-	perfSchemaEventsStatementsRows, err := db.QueryContext(ctx, perfQuery)
+	perfSchemaEventsStatementsRows, err := queryContext(ctx, db, perfQuery)
 	if err != nil {
 		return err
 	}
@@ -193,6 +193,9 @@ func (ScrapePerfEventsStatements) Scrape(ctx context.Context, db *sql.DB, ch cha
 		if err := perfSchemaEventsStatementsRows.Scan(
 			&schemaName, &digest, &digestText, &count, &queryTime, &errors, &warnings, &rowsAffected, &rowsSent, &rowsExamined, &tmpTables, &tmpDiskTables, &sortMergePasses, &sortRows, &noIndexUsed,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
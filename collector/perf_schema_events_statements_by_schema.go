@@ -0,0 +1,118 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_statements_summary_by_digest` grouped by schema.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// perfEventsStatementsBySchemaQuery groups statements run without a default
+// schema, which have a NULL SCHEMA_NAME, under the empty schema.
+const perfEventsStatementsBySchemaQuery = `
+	SELECT
+	    IFNULL(SCHEMA_NAME, '') AS SCHEMA_NAME,
+	    SUM(COUNT_STAR),
+	    SUM(SUM_TIMER_WAIT),
+	    SUM(SUM_ERRORS)
+	  FROM performance_schema.events_statements_summary_by_digest
+	  WHERE IFNULL(SCHEMA_NAME, '') REGEXP ?
+	    AND IFNULL(SCHEMA_NAME, '') NOT REGEXP ?
+	  GROUP BY IFNULL(SCHEMA_NAME, '')
+	`
+
+// Tunable flags.
+var (
+	perfEventsStatementsBySchemaInclude = kingpin.Flag(
+		"collect.perf_schema.eventsstatementsbyschema.schema_include",
+		"RegEx of schemas to collect statement metrics for",
+	).Default(".*").String()
+	perfEventsStatementsBySchemaExclude = kingpin.Flag(
+		"collect.perf_schema.eventsstatementsbyschema.schema_exclude",
+		"RegEx of schemas to skip when collecting statement metrics",
+	).Default("^(mysql|performance_schema|information_schema|sys)$").String()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaEventsStatementsBySchemaTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_schema_total"),
+		"The total count of statements by default schema.",
+		[]string{"schema"}, nil,
+	)
+	performanceSchemaEventsStatementsBySchemaSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_schema_seconds_total"),
+		"The total time of statements by default schema.",
+		[]string{"schema"}, nil,
+	)
+	performanceSchemaEventsStatementsBySchemaErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_schema_errors_total"),
+		"The total number of statements with errors by default schema.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// ScrapeSchemaStatementSummary collects from `performance_schema.events_statements_summary_by_digest`
+// grouped by schema.
+type ScrapeSchemaStatementSummary struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSchemaStatementSummary) Name() string {
+	return "perf_schema.eventsstatementsbyschema"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSchemaStatementSummary) Help() string {
+	return "Collect metrics from performance_schema.events_statements_summary_by_digest grouped by schema"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSchemaStatementSummary) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSchemaStatementSummary) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	bySchemaRows, err := queryContext(ctx, db, perfEventsStatementsBySchemaQuery,
+		*perfEventsStatementsBySchemaInclude, *perfEventsStatementsBySchemaExclude)
+	if err != nil {
+		return err
+	}
+	defer bySchemaRows.Close()
+
+	var (
+		schema                  string
+		count, timeWait, errors uint64
+	)
+	for bySchemaRows.Next() {
+		if err := bySchemaRows.Scan(&schema, &count, &timeWait, &errors); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(performanceSchemaEventsStatementsBySchemaTotalDesc, prometheus.CounterValue, float64(count), schema)
+		newConstMetricFromSeconds(ch, performanceSchemaEventsStatementsBySchemaSecondsDesc, prometheus.CounterValue, timeWait, schema)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaEventsStatementsBySchemaErrorsDesc, prometheus.CounterValue, float64(errors), schema)
+	}
+	return bySchemaRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSchemaStatementSummary{}
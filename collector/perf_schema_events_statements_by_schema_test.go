@@ -0,0 +1,83 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeSchemaStatementSummary(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.eventsstatementsbyschema.schema_include", "^(customer_|$)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"SCHEMA_NAME", "SUM(COUNT_STAR)", "SUM(SUM_TIMER_WAIT)", "SUM(SUM_ERRORS)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("", "7", "500000000000", "1").
+		AddRow("customer_a", "1000", "12000000000000", "3").
+		AddRow("customer_b", "10", "250000000000", "0")
+	mock.ExpectQuery(sanitizeQuery(perfEventsStatementsBySchemaQuery)).
+		WithArgs("^(customer_|$)", "^(mysql|performance_schema|information_schema|sys)$").
+		WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSchemaStatementSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"schema": ""}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": ""}, value: 0.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": ""}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "customer_a"}, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "customer_a"}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "customer_a"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "customer_b"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "customer_b"}, value: 0.25, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "customer_b"}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_statements_summary_by_user_by_event_name`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const perfStatementsByUserQuery = `
+	SELECT
+	    USER,
+	    EVENT_NAME,
+	    COUNT_STAR,
+	    SUM_TIMER_WAIT,
+	    SUM_LOCK_TIME,
+	    SUM_ROWS_SENT,
+	    SUM_ROWS_EXAMINED,
+	    SUM_ROWS_AFFECTED
+	  FROM performance_schema.events_statements_summary_by_user_by_event_name
+	  WHERE COUNT_STAR > 0
+	    AND IFNULL(USER, 'background') REGEXP ?
+	`
+
+// Tunable flags.
+var (
+	perfStatementsByUserInclude = kingpin.Flag(
+		"collect.perf_schema.eventsstatementsbyuser.user_include",
+		"RegEx of users to collect statement metrics for",
+	).Default(".*").String()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaStatementsByUserTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_user_total"),
+		"The total count of statements by user and event name.",
+		[]string{"user", "event_name"}, nil,
+	)
+	performanceSchemaStatementsByUserSecondsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_user_seconds_total"),
+		"The total time of statements by user and event name.",
+		[]string{"user", "event_name"}, nil,
+	)
+	performanceSchemaStatementsByUserLockTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_user_lock_time_seconds_total"),
+		"The total time waiting for table locks of statements by user and event name.",
+		[]string{"user", "event_name"}, nil,
+	)
+	performanceSchemaStatementsByUserRowsSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_user_rows_sent_total"),
+		"The total number of rows returned by statements by user and event name.",
+		[]string{"user", "event_name"}, nil,
+	)
+	performanceSchemaStatementsByUserRowsExaminedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_user_rows_examined_total"),
+		"The total number of rows read from storage engines by statements by user and event name.",
+		[]string{"user", "event_name"}, nil,
+	)
+	performanceSchemaStatementsByUserRowsAffectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_by_user_rows_affected_total"),
+		"The total number of rows affected by statements by user and event name.",
+		[]string{"user", "event_name"}, nil,
+	)
+)
+
+// ScrapePerfStatementsByUser collects from `performance_schema.events_statements_summary_by_user_by_event_name`.
+type ScrapePerfStatementsByUser struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfStatementsByUser) Name() string {
+	return "perf_schema.eventsstatementsbyuser"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfStatementsByUser) Help() string {
+	return "Collect metrics from performance_schema.events_statements_summary_by_user_by_event_name"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfStatementsByUser) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfStatementsByUser) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	byUserRows, err := queryContext(ctx, db, perfStatementsByUserQuery, *perfStatementsByUserInclude)
+	if err != nil {
+		return err
+	}
+	defer byUserRows.Close()
+
+	var (
+		user                                 sql.NullString
+		eventName                            string
+		count, timeWait, lockTime            uint64
+		rowsSent, rowsExamined, rowsAffected uint64
+	)
+	for byUserRows.Next() {
+		if err := byUserRows.Scan(
+			&user, &eventName, &count, &timeWait, &lockTime,
+			&rowsSent, &rowsExamined, &rowsAffected,
+		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		// Statements of background threads have no user, they are reported
+		// as the background user like the sys schema does.
+		userLabel := "background"
+		if user.Valid {
+			userLabel = user.String
+		}
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStatementsByUserTotalDesc, prometheus.CounterValue, uint64ToFloat(ScrapePerfStatementsByUser{}.Name(), "COUNT_STAR", count), userLabel, eventName)
+		newConstMetricFromSeconds(ch, performanceSchemaStatementsByUserSecondsDesc, prometheus.CounterValue, timeWait, userLabel, eventName)
+		newConstMetricFromSeconds(ch, performanceSchemaStatementsByUserLockTimeDesc, prometheus.CounterValue, lockTime, userLabel, eventName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStatementsByUserRowsSentDesc, prometheus.CounterValue, uint64ToFloat(ScrapePerfStatementsByUser{}.Name(), "SUM_ROWS_SENT", rowsSent), userLabel, eventName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStatementsByUserRowsExaminedDesc, prometheus.CounterValue, uint64ToFloat(ScrapePerfStatementsByUser{}.Name(), "SUM_ROWS_EXAMINED", rowsExamined), userLabel, eventName)
+		ch <- prometheus.MustNewConstMetric(performanceSchemaStatementsByUserRowsAffectedDesc, prometheus.CounterValue, uint64ToFloat(ScrapePerfStatementsByUser{}.Name(), "SUM_ROWS_AFFECTED", rowsAffected), userLabel, eventName)
+	}
+	return byUserRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfStatementsByUser{}
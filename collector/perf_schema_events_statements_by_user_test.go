@@ -0,0 +1,87 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapePerfStatementsByUser(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.eventsstatementsbyuser.user_include", "^(app|background)$",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"USER", "EVENT_NAME", "COUNT_STAR", "SUM_TIMER_WAIT", "SUM_LOCK_TIME", "SUM_ROWS_SENT", "SUM_ROWS_EXAMINED", "SUM_ROWS_AFFECTED"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "statement/sql/select", "100", "2500000000000", "1000000000", "500", "10000", "0").
+		AddRow(nil, "statement/sql/insert", "3", "30000000000", "0", "0", "0", "3")
+	mock.ExpectQuery(sanitizeQuery(perfStatementsByUserQuery)).
+		WithArgs("^(app|background)$").
+		WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfStatementsByUser{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	app := labelMap{"user": "app", "event_name": "statement/sql/select"}
+	background := labelMap{"user": "background", "event_name": "statement/sql/insert"}
+	metricExpected := []MetricResult{
+		{labels: app, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: app, value: 2.5, metricType: dto.MetricType_COUNTER},
+		{labels: app, value: 0.001, metricType: dto.MetricType_COUNTER},
+		{labels: app, value: 500, metricType: dto.MetricType_COUNTER},
+		{labels: app, value: 10000, metricType: dto.MetricType_COUNTER},
+		{labels: app, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: background, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: background, value: 0.03, metricType: dto.MetricType_COUNTER},
+		{labels: background, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: background, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: background, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: background, value: 3, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
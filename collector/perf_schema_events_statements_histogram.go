@@ -0,0 +1,137 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_statements_histogram_by_digest`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// perfStatementHistogramQuery joins the histogram of the digests with the
+// most wait time to their summary, which holds the count and sum of the
+// histogram. Empty buckets are skipped, COUNT_BUCKET_AND_LOWER keeps the
+// remaining buckets cumulative.
+const perfStatementHistogramQuery = `
+	SELECT
+	    ifnull(s.SCHEMA_NAME, 'NONE') as SCHEMA_NAME,
+	    s.DIGEST,
+	    s.COUNT_STAR,
+	    s.SUM_TIMER_WAIT,
+	    h.BUCKET_TIMER_HIGH,
+	    h.COUNT_BUCKET_AND_LOWER
+	  FROM (
+	    SELECT SCHEMA_NAME, DIGEST, COUNT_STAR, SUM_TIMER_WAIT
+	    FROM performance_schema.events_statements_summary_by_digest
+	    WHERE DIGEST IS NOT NULL
+	    ORDER BY SUM_TIMER_WAIT DESC
+	    LIMIT ?
+	  ) s
+	  JOIN performance_schema.events_statements_histogram_by_digest h
+	    ON h.SCHEMA_NAME <=> s.SCHEMA_NAME AND h.DIGEST = s.DIGEST
+	  WHERE h.COUNT_BUCKET > 0
+	  ORDER BY s.SUM_TIMER_WAIT DESC, s.SCHEMA_NAME, s.DIGEST, h.BUCKET_NUMBER
+	`
+
+// Tunable flags.
+var (
+	perfStatementHistogramLimit = kingpin.Flag(
+		"collect.perf_schema.eventsstatementshistogram.limit",
+		"Limit the number of statement digests to collect latency histograms for, by total wait time",
+	).Default("50").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaStatementHistogramDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "events_statements_latency_seconds"),
+		"A histogram of the latency of statements by digest.",
+		[]string{"schema", "digest"}, nil,
+	)
+)
+
+// ScrapeStatementHistogram collects from `performance_schema.events_statements_histogram_by_digest`.
+type ScrapeStatementHistogram struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeStatementHistogram) Name() string {
+	return "perf_schema.eventsstatementshistogram"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeStatementHistogram) Help() string {
+	return "Collect statement latency histograms by digest from performance_schema.events_statements_histogram_by_digest"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeStatementHistogram) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeStatementHistogram) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB reports versions above 8.0 but has no statement histograms.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	histogramRows, err := queryContext(ctx, db, perfStatementHistogramQuery, *perfStatementHistogramLimit)
+	if err != nil {
+		return err
+	}
+	defer histogramRows.Close()
+
+	var (
+		schema, digest           string
+		count, timeWait          uint64
+		timerHigh, countAndLower uint64
+		lastSchema, lastDigest   string
+		lastCount, lastTimeWait  uint64
+		buckets                  map[float64]uint64
+	)
+	send := func() {
+		if buckets == nil {
+			return
+		}
+		ch <- prometheus.MustNewConstHistogram(
+			performanceSchemaStatementHistogramDesc, lastCount, float64(lastTimeWait)/picoSeconds, buckets,
+			lastSchema, lastDigest,
+		)
+	}
+	for histogramRows.Next() {
+		if err := histogramRows.Scan(&schema, &digest, &count, &timeWait, &timerHigh, &countAndLower); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if buckets == nil || schema != lastSchema || digest != lastDigest {
+			send()
+			lastSchema, lastDigest, lastCount, lastTimeWait = schema, digest, count, timeWait
+			buckets = map[float64]uint64{}
+		}
+		buckets[float64(timerHigh)/picoSeconds] = countAndLower
+	}
+	if err := histogramRows.Err(); err != nil {
+		return err
+	}
+	send()
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeStatementHistogram{}
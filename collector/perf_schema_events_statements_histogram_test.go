@@ -0,0 +1,103 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeStatementHistogram(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"SCHEMA_NAME", "DIGEST", "COUNT_STAR", "SUM_TIMER_WAIT", "BUCKET_TIMER_HIGH", "COUNT_BUCKET_AND_LOWER"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "abc", "10", "3000000000000", "10000000000", "6").
+		AddRow("app", "abc", "10", "3000000000000", "100000000000", "9").
+		AddRow("app", "abc", "10", "3000000000000", "1000000000000", "10").
+		AddRow("NONE", "def", "2", "2000000", "1000000", "2")
+	mock.ExpectQuery(sanitizeQuery(perfStatementHistogramQuery)).WithArgs(50).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeStatementHistogram{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	type histogram struct {
+		labels  labelMap
+		count   uint64
+		sum     float64
+		buckets map[float64]uint64
+	}
+	var got []histogram
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatal(err)
+		}
+		h := histogram{
+			labels:  labelMap{},
+			count:   pb.GetHistogram().GetSampleCount(),
+			sum:     pb.GetHistogram().GetSampleSum(),
+			buckets: map[float64]uint64{},
+		}
+		for _, l := range pb.Label {
+			h.labels[l.GetName()] = l.GetValue()
+		}
+		for _, b := range pb.GetHistogram().GetBucket() {
+			h.buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+		got = append(got, h)
+	}
+
+	convey.Convey("Histograms comparison", t, func() {
+		convey.So(got, convey.ShouldResemble, []histogram{
+			{
+				labels:  labelMap{"schema": "app", "digest": "abc"},
+				count:   10,
+				sum:     3,
+				buckets: map[float64]uint64{0.01: 6, 0.1: 9, 1: 10},
+			},
+			{
+				labels:  labelMap{"schema": "NONE", "digest": "def"},
+				count:   2,
+				sum:     0.000002,
+				buckets: map[float64]uint64{0.000001: 2},
+			},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -178,7 +178,7 @@ func (ScrapePerfEventsStatementsSum) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfEventsStatementsSum) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	// Timers here are returned in picoseconds.
-	perfEventsStatementsSumRows, err := db.QueryContext(ctx, perfEventsStatementsSumQuery)
+	perfEventsStatementsSumRows, err := queryContext(ctx, db, perfEventsStatementsSumQuery)
 	if err != nil {
 		return err
 	}
@@ -202,6 +202,9 @@ func (ScrapePerfEventsStatementsSum) Scrape(ctx context.Context, db *sql.DB, ch
 			&selectScan, &sortMergePasses, &sortRange, &sortRows,
 			&sortScan, &timerWait, &warnings,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -20,13 +20,23 @@ import (
 	"database/sql"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const perfEventsWaitsQuery = `
 	SELECT EVENT_NAME, COUNT_STAR, SUM_TIMER_WAIT
 	  FROM performance_schema.events_waits_summary_global_by_event_name
+	  WHERE LOCATE(?, EVENT_NAME) = 1
 	`
 
+// Tunable flags.
+var (
+	perfEventsWaitsPrefix = kingpin.Flag(
+		"collect.perf_schema.eventswaits.prefix",
+		"Only collect events whose event_name starts with this prefix, e.g. wait/synch/mutex/innodb",
+	).Default("").String()
+)
+
 // Metric descriptors.
 var (
 	performanceSchemaEventsWaitsDesc = prometheus.NewDesc(
@@ -62,7 +72,7 @@ func (ScrapePerfEventsWaits) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfEventsWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	// Timers here are returned in picoseconds.
-	perfSchemaEventsWaitsRows, err := db.QueryContext(ctx, perfEventsWaitsQuery)
+	perfSchemaEventsWaitsRows, err := queryContext(ctx, db, perfEventsWaitsQuery, *perfEventsWaitsPrefix)
 	if err != nil {
 		return err
 	}
@@ -77,6 +87,9 @@ func (ScrapePerfEventsWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 		if err := perfSchemaEventsWaitsRows.Scan(
 			&eventName, &count, &time,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -88,7 +101,7 @@ func (ScrapePerfEventsWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 			eventName,
 		)
 	}
-	return nil
+	return perfSchemaEventsWaitsRows.Err()
 }
 
 // check interface
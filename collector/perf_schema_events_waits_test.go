@@ -0,0 +1,73 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapePerfEventsWaits(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.eventswaits.prefix", "wait/synch/mutex/innodb",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"EVENT_NAME", "COUNT_STAR", "SUM_TIMER_WAIT"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("wait/synch/mutex/innodb/buf_pool_mutex", "120", "3000000000000").
+		AddRow("wait/synch/mutex/innodb/log_sys_mutex", "7", "250000000")
+	mock.ExpectQuery(sanitizeQuery(perfEventsWaitsQuery)).WithArgs("wait/synch/mutex/innodb").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfEventsWaits{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"event_name": "wait/synch/mutex/innodb/buf_pool_mutex"}, value: 120, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": "wait/synch/mutex/innodb/buf_pool_mutex"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": "wait/synch/mutex/innodb/log_sys_mutex"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": "wait/synch/mutex/innodb/log_sys_mutex"}, value: 0.00025, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -71,7 +71,7 @@ func (ScrapePerfFileEvents) Version() float64 {
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfFileEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
 	// Timers here are returned in picoseconds.
-	perfSchemaFileEventsRows, err := db.QueryContext(ctx, perfFileEventsQuery)
+	perfSchemaFileEventsRows, err := queryContext(ctx, db, perfFileEventsQuery)
 	if err != nil {
 		return err
 	}
@@ -90,6 +90,9 @@ func (ScrapePerfFileEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<- pr
 			&countWrite, &timeWrite, &bytesWrite,
 			&countMisc, &timeMisc,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
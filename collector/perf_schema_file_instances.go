@@ -18,6 +18,7 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -28,9 +29,11 @@ const perfFileInstancesQuery = `
 	SELECT
 	    FILE_NAME, EVENT_NAME,
 	    COUNT_READ, COUNT_WRITE,
-	    SUM_NUMBER_OF_BYTES_READ, SUM_NUMBER_OF_BYTES_WRITE
+	    SUM_NUMBER_OF_BYTES_READ, SUM_NUMBER_OF_BYTES_WRITE,
+	    SUM_TIMER_READ, SUM_TIMER_WRITE
 	  FROM performance_schema.file_summary_by_instance
 	     where FILE_NAME REGEXP ?
+	  ORDER BY SUM_NUMBER_OF_BYTES_READ + SUM_NUMBER_OF_BYTES_WRITE DESC
 	`
 
 // Tunable flags.
@@ -39,6 +42,10 @@ var (
 		"collect.perf_schema.file_instances.filter",
 		"RegEx file_name filter for performance_schema.file_summary_by_instance",
 	).Default(".*").String()
+	performanceSchemaFileInstancesLimit = kingpin.Flag(
+		"collect.perf_schema.file_instances.limit",
+		"Limit the number of files by total bytes read and written, 0 for no limit",
+	).Default("0").Int()
 )
 
 // Metric descriptors.
@@ -58,6 +65,11 @@ var (
 		"The total number of file read/write operations.",
 		[]string{"file_name", "event_name", "mode"}, nil,
 	)
+	performanceSchemaFileInstancesTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "file_instances_seconds_total"),
+		"The total time of file read/write operations.",
+		[]string{"file_name", "event_name", "mode"}, nil,
+	)
 )
 
 // ScrapePerfFileInstances collects from `performance_schema.file_summary_by_instance`.
@@ -80,8 +92,12 @@ func (ScrapePerfFileInstances) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfFileInstances) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	query := perfFileInstancesQuery
+	if *performanceSchemaFileInstancesLimit > 0 {
+		query += fmt.Sprintf("LIMIT %d", *performanceSchemaFileInstancesLimit)
+	}
 	// Timers here are returned in picoseconds.
-	perfSchemaFileInstancesRows, err := db.QueryContext(ctx, perfFileInstancesQuery, *performanceSchemaFileInstancesFilter)
+	perfSchemaFileInstancesRows, err := queryContext(ctx, db, query, *performanceSchemaFileInstancesFilter)
 	if err != nil {
 		return err
 	}
@@ -91,6 +107,7 @@ func (ScrapePerfFileInstances) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 		fileName, eventName           string
 		countRead, countWrite         uint64
 		sumBytesRead, sumBytesWritten uint64
+		timeRead, timeWrite           uint64
 	)
 
 	for perfSchemaFileInstancesRows.Next() {
@@ -98,7 +115,11 @@ func (ScrapePerfFileInstances) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 			&fileName, &eventName,
 			&countRead, &countWrite,
 			&sumBytesRead, &sumBytesWritten,
+			&timeRead, &timeWrite,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
@@ -119,6 +140,14 @@ func (ScrapePerfFileInstances) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 			performanceSchemaFileInstancesBytesDesc, prometheus.CounterValue, float64(sumBytesWritten),
 			fileName, eventName, "write",
 		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaFileInstancesTimeDesc, prometheus.CounterValue, float64(timeRead)/picoSeconds,
+			fileName, eventName, "read",
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaFileInstancesTimeDesc, prometheus.CounterValue, float64(timeWrite)/picoSeconds,
+			fileName, eventName, "write",
+		)
 
 	}
 	return nil
@@ -37,12 +37,12 @@ func TestScrapePerfFileInstances(t *testing.T) {
 	}
 	defer db.Close()
 
-	columns := []string{"FILE_NAME", "EVENT_NAME", "COUNT_READ", "COUNT_WRITE", "SUM_NUMBER_OF_BYTES_READ", "SUM_NUMBER_OF_BYTES_WRITE"}
+	columns := []string{"FILE_NAME", "EVENT_NAME", "COUNT_READ", "COUNT_WRITE", "SUM_NUMBER_OF_BYTES_READ", "SUM_NUMBER_OF_BYTES_WRITE", "SUM_TIMER_READ", "SUM_TIMER_WRITE"}
 
 	rows := sqlmock.NewRows(columns).
-		AddRow("/var/lib/mysql/db1/file", "event1", "3", "4", "725", "128").
-		AddRow("/var/lib/mysql/db2/file", "event2", "23", "12", "3123", "967").
-		AddRow("db3/file", "event3", "45", "32", "1337", "326")
+		AddRow("/var/lib/mysql/db1/file", "event1", "3", "4", "725", "128", "2000000000000", "500000000000").
+		AddRow("/var/lib/mysql/db2/file", "event2", "23", "12", "3123", "967", "0", "0").
+		AddRow("db3/file", "event3", "45", "32", "1337", "326", "1000000000000", "4000000000000")
 	mock.ExpectQuery(sanitizeQuery(perfFileInstancesQuery)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
@@ -58,14 +58,20 @@ func TestScrapePerfFileInstances(t *testing.T) {
 		{labels: labelMap{"file_name": "db1/file", "event_name": "event1", "mode": "write"}, value: 4, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db1/file", "event_name": "event1", "mode": "read"}, value: 725, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db1/file", "event_name": "event1", "mode": "write"}, value: 128, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "db1/file", "event_name": "event1", "mode": "read"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "db1/file", "event_name": "event1", "mode": "write"}, value: 0.5, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db2/file", "event_name": "event2", "mode": "read"}, value: 23, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db2/file", "event_name": "event2", "mode": "write"}, value: 12, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db2/file", "event_name": "event2", "mode": "read"}, value: 3123, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db2/file", "event_name": "event2", "mode": "write"}, value: 967, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "db2/file", "event_name": "event2", "mode": "read"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "db2/file", "event_name": "event2", "mode": "write"}, value: 0, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db3/file", "event_name": "event3", "mode": "read"}, value: 45, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db3/file", "event_name": "event3", "mode": "write"}, value: 32, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db3/file", "event_name": "event3", "mode": "read"}, value: 1337, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"file_name": "db3/file", "event_name": "event3", "mode": "write"}, value: 326, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "db3/file", "event_name": "event3", "mode": "read"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "db3/file", "event_name": "event3", "mode": "write"}, value: 4, metricType: dto.MetricType_COUNTER},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range metricExpected {
@@ -79,3 +85,35 @@ func TestScrapePerfFileInstances(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapePerfFileInstancesLimit(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.perf_schema.file_instances.limit", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"FILE_NAME", "EVENT_NAME", "COUNT_READ", "COUNT_WRITE", "SUM_NUMBER_OF_BYTES_READ", "SUM_NUMBER_OF_BYTES_WRITE", "SUM_TIMER_READ", "SUM_TIMER_WRITE"}
+	mock.ExpectQuery(sanitizeQuery(perfFileInstancesQuery + "LIMIT 2")).WillReturnRows(sqlmock.NewRows(columns))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfFileInstances{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.host_cache`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfHostCacheQuery = `
+	SELECT IP, ifnull(HOST, '') as HOST,
+	    COUNT_HOST_BLOCKED_ERRORS, COUNT_AUTHENTICATION_ERRORS, COUNT_HANDSHAKE_ERRORS
+	  FROM performance_schema.host_cache
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaHostCacheBlockedErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "host_cache_blocked_errors_total"),
+		"The number of connections from the host blocked because of too many errors.",
+		[]string{"ip", "host"}, nil,
+	)
+	performanceSchemaHostCacheAuthenticationErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "host_cache_authentication_errors_total"),
+		"The number of failed authentications from the host.",
+		[]string{"ip", "host"}, nil,
+	)
+	performanceSchemaHostCacheHandshakeErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "host_cache_handshake_errors_total"),
+		"The number of handshake errors from the host.",
+		[]string{"ip", "host"}, nil,
+	)
+)
+
+// ScrapeHostCache collects from `performance_schema.host_cache`.
+type ScrapeHostCache struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeHostCache) Name() string {
+	return "perf_schema.hostcache"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeHostCache) Help() string {
+	return "Collect connection error metrics per client host from performance_schema.host_cache"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeHostCache) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeHostCache) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	hostCacheRows, err := queryContext(ctx, db, perfHostCacheQuery)
+	if err != nil {
+		return err
+	}
+	defer hostCacheRows.Close()
+
+	var (
+		ip, host                                       string
+		blockedErrors, authenticationErrors, handshake uint64
+	)
+
+	for hostCacheRows.Next() {
+		if err := hostCacheRows.Scan(
+			&ip, &host, &blockedErrors, &authenticationErrors, &handshake,
+		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaHostCacheBlockedErrorsDesc, prometheus.CounterValue, float64(blockedErrors),
+			ip, host,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaHostCacheAuthenticationErrorsDesc, prometheus.CounterValue, float64(authenticationErrors),
+			ip, host,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaHostCacheHandshakeErrorsDesc, prometheus.CounterValue, float64(handshake),
+			ip, host,
+		)
+	}
+	return hostCacheRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeHostCache{}
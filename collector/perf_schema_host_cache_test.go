@@ -0,0 +1,68 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeHostCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"IP", "HOST", "COUNT_HOST_BLOCKED_ERRORS", "COUNT_AUTHENTICATION_ERRORS", "COUNT_HANDSHAKE_ERRORS"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("10.0.0.5", "app1.example.com", "0", "3", "1").
+		AddRow("10.0.0.9", "", "12", "0", "100")
+	mock.ExpectQuery(sanitizeQuery(perfHostCacheQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeHostCache{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"ip": "10.0.0.5", "host": "app1.example.com"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"ip": "10.0.0.5", "host": "app1.example.com"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"ip": "10.0.0.5", "host": "app1.example.com"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"ip": "10.0.0.9", "host": ""}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"ip": "10.0.0.9", "host": ""}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"ip": "10.0.0.9", "host": ""}, value: 100, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"database/sql"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const perfIndexIOWaitsQuery = `
@@ -28,8 +29,22 @@ const perfIndexIOWaitsQuery = `
 	    SUM_TIMER_FETCH, SUM_TIMER_INSERT, SUM_TIMER_UPDATE, SUM_TIMER_DELETE
 	  FROM performance_schema.table_io_waits_summary_by_index_usage
 	  WHERE OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema')
+	    AND OBJECT_SCHEMA REGEXP ?
+	    AND OBJECT_NAME REGEXP ?
 	`
 
+// Tunable flags.
+var (
+	perfIndexIOWaitsSchemaFilter = kingpin.Flag(
+		"collect.perf_schema.indexiowaits.schema_filter",
+		"RegEx object_schema filter for performance_schema.table_io_waits_summary_by_index_usage",
+	).Default(".*").String()
+	perfIndexIOWaitsTableFilter = kingpin.Flag(
+		"collect.perf_schema.indexiowaits.table_filter",
+		"RegEx object_name filter for performance_schema.table_io_waits_summary_by_index_usage",
+	).Default(".*").String()
+)
+
 // Metric descriptors.
 var (
 	performanceSchemaIndexWaitsDesc = prometheus.NewDesc(
@@ -64,7 +79,7 @@ func (ScrapePerfIndexIOWaits) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfIndexIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	perfSchemaIndexWaitsRows, err := db.QueryContext(ctx, perfIndexIOWaitsQuery)
+	perfSchemaIndexWaitsRows, err := queryContext(ctx, db, perfIndexIOWaitsQuery, *perfIndexIOWaitsSchemaFilter, *perfIndexIOWaitsTableFilter)
 	if err != nil {
 		return err
 	}
@@ -82,6 +97,9 @@ func (ScrapePerfIndexIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 			&countFetch, &countInsert, &countUpdate, &countDelete,
 			&timeFetch, &timeInsert, &timeUpdate, &timeDelete,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -21,9 +21,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 func TestScrapePerfIndexIOWaits(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.indexiowaits.schema_filter", "^database$",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("error opening a stub database connection: %s", err)
@@ -35,7 +44,7 @@ func TestScrapePerfIndexIOWaits(t *testing.T) {
 		// Note, timers are in picoseconds.
 		AddRow("database", "table", "index", "10", "11", "12", "13", "14000000000000", "15000000000000", "16000000000000", "17000000000000").
 		AddRow("database", "table", "NONE", "20", "21", "22", "23", "24000000000000", "25000000000000", "26000000000000", "27000000000000")
-	mock.ExpectQuery(sanitizeQuery(perfIndexIOWaitsQuery)).WillReturnRows(rows)
+	mock.ExpectQuery(sanitizeQuery(perfIndexIOWaitsQuery)).WithArgs("^database$", ".*").WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
@@ -0,0 +1,116 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.memory_summary_global_by_event_name`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const perfMemoryEventsQuery = `
+	SELECT EVENT_NAME, CURRENT_COUNT_USED, CURRENT_NUMBER_OF_BYTES_USED, HIGH_NUMBER_OF_BYTES_USED
+	  FROM performance_schema.memory_summary_global_by_event_name
+	  WHERE LOCATE(?, EVENT_NAME) = 1
+	`
+
+// Tunable flags.
+var (
+	perfMemoryEventsPrefix = kingpin.Flag(
+		"collect.perf_schema.memoryevents.prefix",
+		"Only collect memory events whose event_name starts with this prefix, e.g. memory/innodb",
+	).Default("memory/").String()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaMemoryEventsCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "memory_events_current_count"),
+		"The number of currently allocated memory blocks by event name.",
+		[]string{"event_name"}, nil,
+	)
+	performanceSchemaMemoryEventsBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "memory_events_current_bytes"),
+		"The number of currently allocated bytes by event name.",
+		[]string{"event_name"}, nil,
+	)
+	performanceSchemaMemoryEventsHighBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "memory_events_high_bytes"),
+		"The high-water mark of allocated bytes by event name.",
+		[]string{"event_name"}, nil,
+	)
+)
+
+// ScrapePerfMemoryGlobal collects from `performance_schema.memory_summary_global_by_event_name`.
+type ScrapePerfMemoryGlobal struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfMemoryGlobal) Name() string {
+	return "perf_schema.memoryevents"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfMemoryGlobal) Help() string {
+	return "Collect metrics from performance_schema.memory_summary_global_by_event_name"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfMemoryGlobal) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfMemoryGlobal) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	perfSchemaMemoryEventsRows, err := queryContext(ctx, db, perfMemoryEventsQuery, *perfMemoryEventsPrefix)
+	if err != nil {
+		return err
+	}
+	defer perfSchemaMemoryEventsRows.Close()
+
+	var (
+		eventName                      string
+		countUsed, bytesUsed, highUsed int64
+	)
+
+	for perfSchemaMemoryEventsRows.Next() {
+		if err := perfSchemaMemoryEventsRows.Scan(
+			&eventName, &countUsed, &bytesUsed, &highUsed,
+		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemoryEventsCountDesc, prometheus.GaugeValue, float64(countUsed),
+			eventName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemoryEventsBytesDesc, prometheus.GaugeValue, float64(bytesUsed),
+			eventName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemoryEventsHighBytesDesc, prometheus.GaugeValue, float64(highUsed),
+			eventName,
+		)
+	}
+	return perfSchemaMemoryEventsRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfMemoryGlobal{}
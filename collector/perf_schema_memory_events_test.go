@@ -0,0 +1,77 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapePerfMemoryGlobal(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.memoryevents.prefix", "memory/innodb",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"EVENT_NAME", "CURRENT_COUNT_USED", "CURRENT_NUMBER_OF_BYTES_USED", "HIGH_NUMBER_OF_BYTES_USED"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("memory/innodb/buf_buf_pool", "4", "137428992", "137428992").
+		AddRow("memory/innodb/hash0hash", "27", "2335344", "2500000")
+	mock.ExpectQuery(sanitizeQuery(perfMemoryEventsQuery)).WithArgs("memory/innodb").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfMemoryGlobal{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"event_name": "memory/innodb/buf_buf_pool"}, value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "memory/innodb/buf_buf_pool"}, value: 137428992, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "memory/innodb/buf_buf_pool"}, value: 137428992, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "memory/innodb/hash0hash"}, value: 27, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "memory/innodb/hash0hash"}, value: 2335344, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "memory/innodb/hash0hash"}, value: 2500000, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
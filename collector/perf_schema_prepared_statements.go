@@ -0,0 +1,130 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.prepared_statements_instances`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const perfPreparedStatementsQuery = `
+	SELECT OWNER_THREAD_ID, COUNT(*), SUM(COUNT_EXECUTE), SUM(SUM_TIMER_EXECUTE)
+	  FROM performance_schema.prepared_statements_instances
+	  GROUP BY OWNER_THREAD_ID
+	`
+
+// Tunable flags.
+var (
+	perfPreparedStatementsByThread = kingpin.Flag(
+		"collect.perf_schema.prepared_statements.by_thread",
+		"Additionally collect the number of prepared statements per owner thread",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaPreparedStatementsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_instances"),
+		"The number of prepared statement instances.",
+		nil, nil,
+	)
+	performanceSchemaPreparedStatementsExecuteDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_execute_total"),
+		"The total number of executions of the current prepared statement instances.",
+		nil, nil,
+	)
+	performanceSchemaPreparedStatementsExecuteTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_execute_seconds_total"),
+		"The total time of executions of the current prepared statement instances.",
+		nil, nil,
+	)
+	performanceSchemaPreparedStatementsByThreadDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "prepared_statements_instances_by_thread"),
+		"The number of prepared statement instances by owner thread.",
+		[]string{"thread_id"}, nil,
+	)
+)
+
+// ScrapePreparedStatements collects from `performance_schema.prepared_statements_instances`.
+type ScrapePreparedStatements struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePreparedStatements) Name() string {
+	return "perf_schema.prepared_statements"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePreparedStatements) Help() string {
+	return "Collect the number and executions of prepared statements from performance_schema.prepared_statements_instances"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePreparedStatements) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePreparedStatements) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB reports versions above 5.7 but only has the table from 10.5.2.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB && !v.atLeast(10, 5, 2) {
+		return nil
+	}
+	preparedRows, err := queryContext(ctx, db, perfPreparedStatementsQuery)
+	if err != nil {
+		return err
+	}
+	defer preparedRows.Close()
+
+	var (
+		threadID                     sql.NullInt64
+		count, executions, timerWait uint64
+		total, totalExecutions       uint64
+		totalTimerWait               uint64
+	)
+	for preparedRows.Next() {
+		if err := preparedRows.Scan(&threadID, &count, &executions, &timerWait); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		total += count
+		totalExecutions += executions
+		totalTimerWait += timerWait
+		if *perfPreparedStatementsByThread {
+			// Statements prepared by stored programs have no owner thread.
+			var thread string
+			if threadID.Valid {
+				thread = strconv.FormatInt(threadID.Int64, 10)
+			}
+			ch <- prometheus.MustNewConstMetric(performanceSchemaPreparedStatementsByThreadDesc, prometheus.GaugeValue, float64(count), thread)
+		}
+	}
+	if err := preparedRows.Err(); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(performanceSchemaPreparedStatementsDesc, prometheus.GaugeValue, float64(total))
+	ch <- prometheus.MustNewConstMetric(performanceSchemaPreparedStatementsExecuteDesc, prometheus.CounterValue, float64(totalExecutions))
+	newConstMetricFromSeconds(ch, performanceSchemaPreparedStatementsExecuteTimeDesc, prometheus.CounterValue, totalTimerWait)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePreparedStatements{}
@@ -0,0 +1,130 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.replication_applier_status_by_coordinator`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfReplicationApplierStatusByCoordinatorQuery = `
+	SELECT CHANNEL_NAME, SERVICE_STATE, LAST_ERROR_NUMBER, LAST_ERROR_TIMESTAMP
+	  FROM performance_schema.replication_applier_status_by_coordinator
+	`
+
+// replicationServiceStates maps SERVICE_STATE to the value of the service
+// state gauge.
+var replicationServiceStates = map[string]float64{
+	"OFF":        0,
+	"ON":         1,
+	"CONNECTING": 2,
+}
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationApplierCoordinatorServiceStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_coordinator_service_state"),
+		"The state of the coordinator thread of the channel: 0 OFF, 1 ON, 2 CONNECTING, -1 unknown.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationApplierCoordinatorLastErrorNumberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_coordinator_last_error_number"),
+		"The number of the last error of the coordinator thread of the channel, 0 without error.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationApplierCoordinatorLastErrorTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_coordinator_last_error_timestamp_seconds"),
+		"The time of the last error of the coordinator thread of the channel, 0 without error.",
+		[]string{"channel_name"}, nil,
+	)
+)
+
+// ScrapeApplierCoordinator collects from `performance_schema.replication_applier_status_by_coordinator`.
+type ScrapeApplierCoordinator struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeApplierCoordinator) Name() string {
+	return performanceSchema + ".replication_applier_status_by_coordinator"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeApplierCoordinator) Help() string {
+	return "Collect the state and last error of the multi-threaded replica coordinator from performance_schema.replication_applier_status_by_coordinator"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeApplierCoordinator) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeApplierCoordinator) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB reports versions above 5.7 but has no replication tables.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	// The table has no rows without replication or a multi-threaded replica.
+	coordinatorRows, err := queryContext(ctx, db, perfReplicationApplierStatusByCoordinatorQuery)
+	if err != nil {
+		return err
+	}
+	defer coordinatorRows.Close()
+
+	var (
+		channelName, serviceState, lastErrorTime string
+		lastErrorNumber                          uint64
+	)
+	for coordinatorRows.Next() {
+		if err := coordinatorRows.Scan(&channelName, &serviceState, &lastErrorNumber, &lastErrorTime); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierCoordinatorServiceStateDesc, prometheus.GaugeValue,
+			replicationServiceState(serviceState), channelName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierCoordinatorLastErrorNumberDesc, prometheus.GaugeValue,
+			float64(lastErrorNumber), channelName,
+		)
+		// Without error the timestamp is the zero date, which does not parse.
+		var lastError float64
+		if t, err := parseMySQLTime(lastErrorTime); err == nil {
+			lastError = float64(t.UnixNano()) / 1e9
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierCoordinatorLastErrorTimeDesc, prometheus.GaugeValue,
+			lastError, channelName,
+		)
+	}
+	return coordinatorRows.Err()
+}
+
+// replicationServiceState returns the gauge value of SERVICE_STATE, -1 for
+// unknown states.
+func replicationServiceState(state string) float64 {
+	if value, ok := replicationServiceStates[state]; ok {
+		return value
+	}
+	return -1
+}
+
+// check interface
+var _ Scraper = ScrapeApplierCoordinator{}
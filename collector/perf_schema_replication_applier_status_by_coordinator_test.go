@@ -0,0 +1,76 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeApplierCoordinator(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"CHANNEL_NAME", "SERVICE_STATE", "LAST_ERROR_NUMBER", "LAST_ERROR_TIMESTAMP"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("", "ON", "0", "0000-00-00 00:00:00.000000").
+		AddRow("east", "OFF", "1062", "2023-05-04 10:00:00.500000").
+		AddRow("west", "CONNECTING", "0", "0000-00-00 00:00:00.000000").
+		AddRow("north", "STARTING", "0", "0000-00-00 00:00:00.000000")
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierStatusByCoordinatorQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeApplierCoordinator{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"channel_name": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": ""}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": ""}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "east"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "east"}, value: 1062, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "east"}, value: 1683194400.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "west"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "west"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "west"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "north"}, value: -1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "north"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "north"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
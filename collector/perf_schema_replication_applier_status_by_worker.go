@@ -25,6 +25,7 @@ const perfReplicationApplierStatsByWorkerQuery = `
 	SELECT 
 	    CHANNEL_NAME,
 		WORKER_ID,
+		SERVICE_STATE,
 		LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP,
 		LAST_APPLIED_TRANSACTION_IMMEDIATE_COMMIT_TIMESTAMP,
 		LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP,
@@ -38,6 +39,12 @@ const timeLayout = "2006-01-02 15:04:05.000000"
 
 // Metric descriptors.
 var (
+	performanceSchemaReplicationApplierStatsByWorkerServiceStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_worker_service_state"),
+		"Whether the worker thread is active or idle (1) or not running (0).",
+		[]string{"channel_name", "member_id"}, nil,
+	)
+
 	performanceSchemaReplicationApplierStatsByWorkerLastAppliedTransactionOriginalCommitSecondDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, performanceSchema, "last_applied_transaction_original_commit_timestamp_seconds"),
 		"A timestamp shows when the last transaction applied by this worker was committed on the original master.",
@@ -101,14 +108,14 @@ func (ScrapePerfReplicationApplierStatsByWorker) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfReplicationApplierStatsByWorker) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	perfReplicationApplierStatsByWorkerRows, err := db.QueryContext(ctx, perfReplicationApplierStatsByWorkerQuery)
+	perfReplicationApplierStatsByWorkerRows, err := queryContext(ctx, db, perfReplicationApplierStatsByWorkerQuery)
 	if err != nil {
 		return err
 	}
 	defer perfReplicationApplierStatsByWorkerRows.Close()
 
 	var (
-		channelName, workerId                                                                     string
+		channelName, workerId, serviceState                                                       string
 		lastAppliedTransactionOriginalCommit, lastAppliedTransactionImmediateCommit               string
 		lastAppliedTransactionStartApply, lastAppliedTransactionEndApply                          string
 		applyingTransactionOriginalCommit, applyingTransactionImmediateCommit                     string
@@ -121,15 +128,25 @@ func (ScrapePerfReplicationApplierStatsByWorker) Scrape(ctx context.Context, db
 
 	for perfReplicationApplierStatsByWorkerRows.Next() {
 		if err := perfReplicationApplierStatsByWorkerRows.Scan(
-			&channelName, &workerId,
+			&channelName, &workerId, &serviceState,
 			&lastAppliedTransactionOriginalCommit, &lastAppliedTransactionImmediateCommit,
 			&lastAppliedTransactionStartApply, &lastAppliedTransactionEndApply,
 			&applyingTransactionOriginalCommit, &applyingTransactionImmediateCommit,
 			&applyingTransactionStartApply,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
+		if value, ok := parseStatus(sql.RawBytes(serviceState)); ok {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationApplierStatsByWorkerServiceStateDesc,
+				prometheus.GaugeValue, value, channelName, workerId,
+			)
+		}
+
 		lastAppliedTransactionOriginalCommitTime, err := time.Parse(timeLayout, lastAppliedTransactionOriginalCommit)
 		if err != nil {
 			lastAppliedTransactionOriginalCommitTime = time.Time{}
@@ -34,6 +34,7 @@ func TestScrapePerfReplicationApplierStatsByWorker(t *testing.T) {
 	columns := []string{
 		"CHANNEL_NAME",
 		"WORKER_ID",
+		"SERVICE_STATE",
 		"LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP",
 		"LAST_APPLIED_TRANSACTION_IMMEDIATE_COMMIT_TIMESTAMP",
 		"LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP",
@@ -47,8 +48,8 @@ func TestScrapePerfReplicationApplierStatsByWorker(t *testing.T) {
 
 	stubTime := time.Date(2019, 3, 14, 0, 0, 0, int(time.Millisecond), time.UTC)
 	rows := sqlmock.NewRows(columns).
-		AddRow("dummy_0", "0", timeZero, timeZero, timeZero, timeZero, timeZero, timeZero, timeZero).
-		AddRow("dummy_1", "1", stubTime.Format(timeLayout), stubTime.Add(1*time.Minute).Format(timeLayout), stubTime.Add(2*time.Minute).Format(timeLayout), stubTime.Add(3*time.Minute).Format(timeLayout), stubTime.Add(4*time.Minute).Format(timeLayout), stubTime.Add(5*time.Minute).Format(timeLayout), stubTime.Add(6*time.Minute).Format(timeLayout))
+		AddRow("dummy_0", "0", "OFF", timeZero, timeZero, timeZero, timeZero, timeZero, timeZero, timeZero).
+		AddRow("dummy_1", "1", "ON", stubTime.Format(timeLayout), stubTime.Add(1*time.Minute).Format(timeLayout), stubTime.Add(2*time.Minute).Format(timeLayout), stubTime.Add(3*time.Minute).Format(timeLayout), stubTime.Add(4*time.Minute).Format(timeLayout), stubTime.Add(5*time.Minute).Format(timeLayout), stubTime.Add(6*time.Minute).Format(timeLayout))
 	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierStatsByWorkerQuery)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
@@ -67,6 +68,8 @@ func TestScrapePerfReplicationApplierStatsByWorker(t *testing.T) {
 		{labels: labelMap{"channel_name": "dummy_0", "member_id": "0"}, value: 0, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"channel_name": "dummy_0", "member_id": "0"}, value: 0, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"channel_name": "dummy_0", "member_id": "0"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "dummy_0", "member_id": "0"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "dummy_1", "member_id": "1"}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"channel_name": "dummy_1", "member_id": "1"}, value: 1.552521600001e+9, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"channel_name": "dummy_1", "member_id": "1"}, value: 1.552521660001e+9, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"channel_name": "dummy_1", "member_id": "1"}, value: 1.552521720001e+9, metricType: dto.MetricType_GAUGE},
@@ -70,7 +70,7 @@ func (ScrapePerfReplicationGroupMemberStats) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfReplicationGroupMemberStats) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	perfReplicationGroupMemeberStatsRows, err := db.QueryContext(ctx, perfReplicationGroupMemeberStatsQuery)
+	perfReplicationGroupMemeberStatsRows, err := queryContext(ctx, db, perfReplicationGroupMemeberStatsQuery)
 	if err != nil {
 		return err
 	}
@@ -87,6 +87,9 @@ func (ScrapePerfReplicationGroupMemberStats) Scrape(ctx context.Context, db *sql
 			&memberId, &countTransactionsInQueue, &countTransactionsChecked,
 			&countConflictsDetected, &countTransactionsRowsValidating,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
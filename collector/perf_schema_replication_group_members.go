@@ -0,0 +1,155 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.replication_group_members`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MEMBER_ROLE was added in MySQL 8.0.2, older servers report an empty role.
+const (
+	perfReplicationGroupMembersQuery = `
+	SELECT MEMBER_ID, MEMBER_HOST, MEMBER_PORT, MEMBER_STATE, MEMBER_ROLE
+	  FROM performance_schema.replication_group_members
+	`
+	perfReplicationGroupMembersQuery57 = `
+	SELECT MEMBER_ID, MEMBER_HOST, MEMBER_PORT, MEMBER_STATE, ''
+	  FROM performance_schema.replication_group_members
+	`
+)
+
+// replicationGroupMemberStates maps MEMBER_STATE to the value of the
+// member state gauge.
+var replicationGroupMemberStates = map[string]float64{
+	"OFFLINE":     0,
+	"ONLINE":      1,
+	"RECOVERING":  2,
+	"UNREACHABLE": 3,
+	"ERROR":       4,
+}
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationGroupMemberInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_info"),
+		"Information about the members of the replication group, always 1.",
+		[]string{"member_id", "member_host", "member_port", "member_role"}, nil,
+	)
+	performanceSchemaReplicationGroupMemberStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_state"),
+		"The state of the member of the replication group: 0 OFFLINE, 1 ONLINE, 2 RECOVERING, 3 UNREACHABLE, 4 ERROR, -1 unknown.",
+		[]string{"member_id"}, nil,
+	)
+	performanceSchemaReplicationGroupOnlineMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_online_members"),
+		"The number of ONLINE members of the replication group.",
+		nil, nil,
+	)
+)
+
+// ScrapeGroupReplicationMembers collects from `performance_schema.replication_group_members`.
+type ScrapeGroupReplicationMembers struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeGroupReplicationMembers) Name() string {
+	return performanceSchema + ".replication_group_members"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeGroupReplicationMembers) Help() string {
+	return "Collect the state and role of the group replication members from performance_schema.replication_group_members"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeGroupReplicationMembers) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeGroupReplicationMembers) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	query := perfReplicationGroupMembersQuery
+	if v, ok := serverVersionFromContext(ctx); ok {
+		// Group replication exists since MySQL 5.7.17, not in MariaDB.
+		if v.Flavor == flavorMariaDB || !v.atLeast(5, 7, 17) {
+			return nil
+		}
+		if !v.atLeast(8, 0, 2) {
+			query = perfReplicationGroupMembersQuery57
+		}
+	}
+	membersRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return err
+	}
+	defer membersRows.Close()
+
+	var (
+		memberID, memberHost, memberState, memberRole string
+		memberPort                                    sql.NullInt64
+		members, online                               int
+	)
+	for membersRows.Next() {
+		if err := membersRows.Scan(&memberID, &memberHost, &memberPort, &memberState, &memberRole); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		// Without an active plugin a single OFFLINE row without id is reported.
+		if memberID == "" {
+			continue
+		}
+		members++
+		if memberState == "ONLINE" {
+			online++
+		}
+		port := ""
+		if memberPort.Valid {
+			port = strconv.FormatInt(memberPort.Int64, 10)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationGroupMemberInfoDesc, prometheus.GaugeValue, 1,
+			memberID, memberHost, port, memberRole,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationGroupMemberStateDesc, prometheus.GaugeValue, replicationGroupMemberState(memberState),
+			memberID,
+		)
+	}
+	if err := membersRows.Err(); err != nil {
+		return err
+	}
+	if members > 0 {
+		ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationGroupOnlineMembersDesc, prometheus.GaugeValue, float64(online))
+	}
+	return nil
+}
+
+// replicationGroupMemberState returns the gauge value of MEMBER_STATE, -1 for
+// unknown states.
+func replicationGroupMemberState(state string) float64 {
+	if value, ok := replicationGroupMemberStates[state]; ok {
+		return value
+	}
+	return -1
+}
+
+// check interface
+var _ Scraper = ScrapeGroupReplicationMembers{}
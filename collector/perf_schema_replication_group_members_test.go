@@ -0,0 +1,136 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var perfReplicationGroupMembersColumns = []string{"MEMBER_ID", "MEMBER_HOST", "MEMBER_PORT", "MEMBER_STATE", "MEMBER_ROLE"}
+
+func TestReplicationGroupMemberState(t *testing.T) {
+	convey.Convey("Member states are mapped to gauge values", t, func() {
+		for state, expected := range map[string]float64{
+			"OFFLINE":     0,
+			"ONLINE":      1,
+			"RECOVERING":  2,
+			"UNREACHABLE": 3,
+			"ERROR":       4,
+			"":            -1,
+			"FUTURE":      -1,
+		} {
+			convey.So(replicationGroupMemberState(state), convey.ShouldEqual, expected)
+		}
+	})
+}
+
+func TestScrapeGroupReplicationMembers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(perfReplicationGroupMembersColumns).
+		AddRow("uuid-1", "db1", "3306", "ONLINE", "PRIMARY").
+		AddRow("uuid-2", "db2", "3306", "RECOVERING", "SECONDARY").
+		AddRow("uuid-3", "db3", nil, "UNREACHABLE", "SECONDARY")
+	mock.ExpectQuery(sanitizeQuery(perfReplicationGroupMembersQuery)).WillReturnRows(rows)
+
+	ctx := withServerVersion(context.Background(), parseServerVersion("8.0.33", "MySQL Community Server - GPL"))
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGroupReplicationMembers{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"member_id": "uuid-1", "member_host": "db1", "member_port": "3306", "member_role": "PRIMARY"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid-1"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid-2", "member_host": "db2", "member_port": "3306", "member_role": "SECONDARY"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid-2"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid-3", "member_host": "db3", "member_port": "", "member_role": "SECONDARY"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"member_id": "uuid-3"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeGroupReplicationMembersInactive(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		rows    *sqlmock.Rows
+	}{
+		{name: "plugin not installed", version: "8.0.33", rows: sqlmock.NewRows(perfReplicationGroupMembersColumns)},
+		{name: "plugin not running", version: "8.0.33", rows: sqlmock.NewRows(perfReplicationGroupMembersColumns).
+			AddRow("", "", nil, "OFFLINE", "")},
+		{name: "MySQL 5.7 without member role", version: "5.7.40", rows: sqlmock.NewRows(perfReplicationGroupMembersColumns)},
+		{name: "MySQL before group replication", version: "5.7.16"},
+	}
+	for _, tt := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		if tt.rows != nil {
+			query := perfReplicationGroupMembersQuery
+			if tt.version == "5.7.40" {
+				query = perfReplicationGroupMembersQuery57
+			}
+			mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(tt.rows)
+		}
+
+		ctx := withServerVersion(context.Background(), parseServerVersion(tt.version, ""))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeGroupReplicationMembers{}).Scrape(ctx, db, ch); err != nil {
+				t.Errorf("%s: error calling function on test: %s", tt.name, err)
+			}
+			close(ch)
+		}()
+
+		convey.Convey("No metrics with "+tt.name, t, func() {
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: there were unfulfilled exceptions: %s", tt.name, err)
+		}
+		db.Close()
+	}
+}
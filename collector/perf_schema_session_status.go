@@ -0,0 +1,92 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.session_status`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// perfSessionStatusQuery reads the status of the session running the scrape,
+// which serves as a baseline of the load caused by the exporter itself.
+const perfSessionStatusQuery = `
+	SELECT VARIABLE_NAME, VARIABLE_VALUE
+	  FROM performance_schema.session_status
+	  WHERE VARIABLE_NAME IN ('Bytes_received', 'Bytes_sent', 'Handler_read_rnd_next', 'Created_tmp_disk_tables')
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaSessionStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "session_status"),
+		"The value of a session status variable of the exporter connection.",
+		[]string{"variable"}, nil,
+	)
+)
+
+// ScrapeSessionStatus collects from `performance_schema.session_status`.
+type ScrapeSessionStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSessionStatus) Name() string {
+	return "perf_schema.session_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSessionStatus) Help() string {
+	return "Collect status variables of the exporter session from performance_schema.session_status"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSessionStatus) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSessionStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB only has the status in information_schema.session_status.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	sessionStatusRows, err := queryContext(ctx, db, perfSessionStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer sessionStatusRows.Close()
+
+	var name string
+	var value sql.RawBytes
+	for sessionStatusRows.Next() {
+		if err := sessionStatusRows.Scan(&name, &value); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if floatVal, ok := parseStatus(value); ok {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaSessionStatusDesc, prometheus.GaugeValue, floatVal, strings.ToLower(name),
+			)
+		}
+	}
+	return sessionStatusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSessionStatus{}
@@ -0,0 +1,90 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.setup_instruments`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfSetupInstrumentsQuery = `
+	SELECT SUBSTRING_INDEX(NAME, '/', 1) AS PREFIX, ENABLED, COUNT(*)
+	  FROM performance_schema.setup_instruments
+	  GROUP BY PREFIX, ENABLED
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaSetupInstrumentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_instruments"),
+		"The number of enabled and disabled instruments by top-level prefix.",
+		[]string{"prefix", "state"}, nil,
+	)
+)
+
+// ScrapeSetupInstruments collects from `performance_schema.setup_instruments`.
+type ScrapeSetupInstruments struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSetupInstruments) Name() string {
+	return "perf_schema.setup_instruments"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSetupInstruments) Help() string {
+	return "Collect the number of enabled and disabled instruments from performance_schema.setup_instruments"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSetupInstruments) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSetupInstruments) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	instrumentsRows, err := queryContext(ctx, db, perfSetupInstrumentsQuery)
+	if err != nil {
+		return err
+	}
+	defer instrumentsRows.Close()
+
+	var (
+		prefix, enabled string
+		count           uint64
+	)
+	for instrumentsRows.Next() {
+		if err := instrumentsRows.Scan(&prefix, &enabled, &count); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		state := "disabled"
+		if enabled == "YES" {
+			state = "enabled"
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaSetupInstrumentsDesc, prometheus.GaugeValue, float64(count),
+			prefix, state,
+		)
+	}
+	return instrumentsRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSetupInstruments{}
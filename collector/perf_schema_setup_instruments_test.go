@@ -0,0 +1,72 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSetupInstruments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"PREFIX", "ENABLED", "COUNT(*)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("memory", "NO", "310").
+		AddRow("memory", "YES", "70").
+		AddRow("stage", "NO", "100").
+		AddRow("statement", "YES", "190").
+		AddRow("wait", "NO", "280").
+		AddRow("wait", "YES", "60")
+	mock.ExpectQuery(sanitizeQuery(perfSetupInstrumentsQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSetupInstruments{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"prefix": "memory", "state": "disabled"}, value: 310, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"prefix": "memory", "state": "enabled"}, value: 70, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"prefix": "stage", "state": "disabled"}, value: 100, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"prefix": "statement", "state": "enabled"}, value: 190, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"prefix": "wait", "state": "disabled"}, value: 280, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"prefix": "wait", "state": "enabled"}, value: 60, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_stages_summary_by_account_by_event_name`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// perfStagesByAccountQuery orders the stages of each account by wait time,
+// the per account limit is applied while reading the rows as window
+// functions are not available before MySQL 8.0.
+const perfStagesByAccountQuery = `
+	SELECT IFNULL(USER, ''), IFNULL(HOST, ''), EVENT_NAME, COUNT_STAR, SUM_TIMER_WAIT
+	  FROM performance_schema.events_stages_summary_by_account_by_event_name
+	  WHERE LOCATE(?, EVENT_NAME) = 1
+	    AND COUNT_STAR > 0
+	  ORDER BY USER, HOST, SUM_TIMER_WAIT DESC
+	`
+
+// Tunable flags.
+var (
+	perfStagesByAccountPrefix = kingpin.Flag(
+		"collect.perf_schema.stagesbyaccount.prefix",
+		"Only collect stages whose event_name starts with this prefix, e.g. stage/innodb",
+	).Default("stage/sql/").String()
+	perfStagesByAccountLimit = kingpin.Flag(
+		"collect.perf_schema.stagesbyaccount.limit",
+		"Limit the number of stages per account by total wait time, 0 for no limit",
+	).Default("10").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaStagesByAccountCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "stages_by_account_total"),
+		"The total number of stage events by account and event name.",
+		[]string{"user", "host", "event_name"}, nil,
+	)
+	performanceSchemaStagesByAccountTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "stages_by_account_seconds_total"),
+		"The total time of stage events by account and event name.",
+		[]string{"user", "host", "event_name"}, nil,
+	)
+)
+
+// ScrapePerfStagesByAccount collects from `performance_schema.events_stages_summary_by_account_by_event_name`.
+type ScrapePerfStagesByAccount struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfStagesByAccount) Name() string {
+	return "perf_schema.stagesbyaccount"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfStagesByAccount) Help() string {
+	return "Collect metrics from performance_schema.events_stages_summary_by_account_by_event_name"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfStagesByAccount) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfStagesByAccount) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// Stages of all accounts are too many series to collect unfiltered.
+	if *perfStagesByAccountPrefix == "" {
+		return errors.New("collect.perf_schema.stagesbyaccount.prefix must not be empty")
+	}
+	stagesRows, err := queryContext(ctx, db, perfStagesByAccountQuery, *perfStagesByAccountPrefix)
+	if err != nil {
+		return err
+	}
+	defer stagesRows.Close()
+
+	var (
+		user, host, eventName string
+		count, timeWait       uint64
+		lastUser, lastHost    string
+		accountStages         int
+	)
+	for stagesRows.Next() {
+		if err := stagesRows.Scan(&user, &host, &eventName, &count, &timeWait); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if user != lastUser || host != lastHost {
+			lastUser, lastHost = user, host
+			accountStages = 0
+		}
+		accountStages++
+		if *perfStagesByAccountLimit > 0 && accountStages > *perfStagesByAccountLimit {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaStagesByAccountCountDesc, prometheus.CounterValue, float64(count),
+			user, host, eventName,
+		)
+		newConstMetricFromSeconds(ch, performanceSchemaStagesByAccountTimeDesc, prometheus.CounterValue, timeWait, user, host, eventName)
+	}
+	return stagesRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfStagesByAccount{}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapePerfStagesByAccount(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.stagesbyaccount.prefix", "stage/sql/Sending",
+		"--collect.perf_schema.stagesbyaccount.limit", "1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"USER", "HOST", "EVENT_NAME", "COUNT_STAR", "SUM_TIMER_WAIT"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "10.0.0.1", "stage/sql/Sending data", "120", "3000000000000").
+		AddRow("app", "10.0.0.1", "stage/sql/Sending to client", "80", "1000000000000").
+		AddRow("report", "10.0.0.2", "stage/sql/Sending data", "5", "500000000000")
+	mock.ExpectQuery(sanitizeQuery(perfStagesByAccountQuery)).WithArgs("stage/sql/Sending").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfStagesByAccount{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	app := labelMap{"user": "app", "host": "10.0.0.1", "event_name": "stage/sql/Sending data"}
+	report := labelMap{"user": "report", "host": "10.0.0.2", "event_name": "stage/sql/Sending data"}
+	metricExpected := []MetricResult{
+		{labels: app, value: 120, metricType: dto.MetricType_COUNTER},
+		{labels: app, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: report, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: report, value: 0.5, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapePerfStagesByAccountEmptyPrefix(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.stagesbyaccount.prefix", "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	ch := make(chan prometheus.Metric, 1)
+	err = (ScrapePerfStagesByAccount{}).Scrape(context.Background(), db, ch)
+
+	convey.Convey("Unfiltered stages are refused", t, func() {
+		convey.So(err, convey.ShouldBeError, "collect.perf_schema.stagesbyaccount.prefix must not be empty")
+	})
+
+	// Ensure no SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -18,8 +18,10 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const perfTableIOWaitsQuery = `
@@ -29,8 +31,27 @@ const perfTableIOWaitsQuery = `
 	    SUM_TIMER_FETCH, SUM_TIMER_INSERT, SUM_TIMER_UPDATE, SUM_TIMER_DELETE
 	  FROM performance_schema.table_io_waits_summary_by_table
 	  WHERE OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema')
+	    AND OBJECT_SCHEMA REGEXP ?
+	    AND OBJECT_NAME REGEXP ?
+	  ORDER BY SUM_TIMER_WAIT DESC
 	`
 
+// Tunable flags.
+var (
+	perfTableIOWaitsSchemaFilter = kingpin.Flag(
+		"collect.perf_schema.tableiowaits.schema_filter",
+		"RegEx object_schema filter for performance_schema.table_io_waits_summary_by_table",
+	).Default(".*").String()
+	perfTableIOWaitsTableFilter = kingpin.Flag(
+		"collect.perf_schema.tableiowaits.table_filter",
+		"RegEx object_name filter for performance_schema.table_io_waits_summary_by_table",
+	).Default(".*").String()
+	perfTableIOWaitsLimit = kingpin.Flag(
+		"collect.perf_schema.tableiowaits.limit",
+		"Limit the number of tables by total I/O wait time, 0 for no limit",
+	).Default("0").Int()
+)
+
 // Metric descriptors.
 var (
 	performanceSchemaTableWaitsDesc = prometheus.NewDesc(
@@ -65,7 +86,13 @@ func (ScrapePerfTableIOWaits) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfTableIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	perfSchemaTableWaitsRows, err := db.QueryContext(ctx, perfTableIOWaitsQuery)
+	query := perfTableIOWaitsQuery
+	if *perfTableIOWaitsLimit > 0 {
+		query += fmt.Sprintf("LIMIT %d", *perfTableIOWaitsLimit)
+	}
+	perfSchemaTableWaitsRows, err := queryContext(ctx, db, query,
+		*perfTableIOWaitsSchemaFilter, *perfTableIOWaitsTableFilter,
+	)
 	if err != nil {
 		return err
 	}
@@ -82,6 +109,9 @@ func (ScrapePerfTableIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 			&objectSchema, &objectName, &countFetch, &countInsert, &countUpdate, &countDelete,
 			&timeFetch, &timeInsert, &timeUpdate, &timeDelete,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
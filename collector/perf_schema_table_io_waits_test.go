@@ -0,0 +1,80 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapePerfTableIOWaits(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.tableiowaits.table_filter", "^orders$",
+		"--collect.perf_schema.tableiowaits.limit", "5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"OBJECT_SCHEMA", "OBJECT_NAME", "COUNT_FETCH", "COUNT_INSERT", "COUNT_UPDATE", "COUNT_DELETE", "SUM_TIMER_FETCH", "SUM_TIMER_INSERT", "SUM_TIMER_UPDATE", "SUM_TIMER_DELETE"}
+	rows := sqlmock.NewRows(columns).
+		// Note, timers are in picoseconds.
+		AddRow("shop", "orders", "1", "2", "3", "4", "5000000000000", "6000000000000", "7000000000000", "500000000")
+	mock.ExpectQuery(sanitizeQuery(perfTableIOWaitsQuery+"LIMIT 5")).WithArgs(".*", "^orders$").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfTableIOWaits{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "fetch"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "insert"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "update"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "delete"}, value: 4, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "fetch"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "insert"}, value: 6, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "update"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "delete"}, value: 0.0005, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -18,8 +18,10 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
 const perfTableLockWaitsQuery = `
@@ -48,8 +50,27 @@ const perfTableLockWaitsQuery = `
 	    SUM_TIMER_WRITE_EXTERNAL
 	  FROM performance_schema.table_lock_waits_summary_by_table
 	  WHERE OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema')
+	    AND OBJECT_SCHEMA REGEXP ?
+	    AND OBJECT_NAME REGEXP ?
+	  ORDER BY SUM_TIMER_WAIT DESC
 	`
 
+// Tunable flags.
+var (
+	perfTableLockWaitsSchemaFilter = kingpin.Flag(
+		"collect.perf_schema.tablelocks.schema_filter",
+		"RegEx object_schema filter for performance_schema.table_lock_waits_summary_by_table",
+	).Default(".*").String()
+	perfTableLockWaitsTableFilter = kingpin.Flag(
+		"collect.perf_schema.tablelocks.table_filter",
+		"RegEx object_name filter for performance_schema.table_lock_waits_summary_by_table",
+	).Default(".*").String()
+	perfTableLockWaitsLimit = kingpin.Flag(
+		"collect.perf_schema.tablelocks.limit",
+		"Limit the number of tables by total lock wait time, 0 for no limit",
+	).Default("0").Int()
+)
+
 // Metric descriptors.
 var (
 	performanceSchemaSQLTableLockWaitsDesc = prometheus.NewDesc(
@@ -94,7 +115,13 @@ func (ScrapePerfTableLockWaits) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	perfSchemaTableLockWaitsRows, err := db.QueryContext(ctx, perfTableLockWaitsQuery)
+	query := perfTableLockWaitsQuery
+	if *perfTableLockWaitsLimit > 0 {
+		query += fmt.Sprintf("LIMIT %d", *perfTableLockWaitsLimit)
+	}
+	perfSchemaTableLockWaitsRows, err := queryContext(ctx, db, query,
+		*perfTableLockWaitsSchemaFilter, *perfTableLockWaitsTableFilter,
+	)
 	if err != nil {
 		return err
 	}
@@ -150,6 +177,9 @@ func (ScrapePerfTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<
 			&timeWriteNormal,
 			&timeWriteExternal,
 		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 		ch <- prometheus.MustNewConstMetric(
@@ -233,7 +263,7 @@ func (ScrapePerfTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<
 			objectSchema, objectName, "write",
 		)
 	}
-	return nil
+	return perfSchemaTableLockWaitsRows.Err()
 }
 
 // check interface
@@ -0,0 +1,90 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapePerfTableLockWaits(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.tablelocks.schema_filter", "^shop$",
+		"--collect.perf_schema.tablelocks.limit", "5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"OBJECT_SCHEMA", "OBJECT_NAME", "COUNT_READ_NORMAL", "COUNT_READ_WITH_SHARED_LOCKS", "COUNT_READ_HIGH_PRIORITY", "COUNT_READ_NO_INSERT", "COUNT_READ_EXTERNAL", "COUNT_WRITE_ALLOW_WRITE", "COUNT_WRITE_CONCURRENT_INSERT", "COUNT_WRITE_LOW_PRIORITY", "COUNT_WRITE_NORMAL", "COUNT_WRITE_EXTERNAL", "SUM_TIMER_READ_NORMAL", "SUM_TIMER_READ_WITH_SHARED_LOCKS", "SUM_TIMER_READ_HIGH_PRIORITY", "SUM_TIMER_READ_NO_INSERT", "SUM_TIMER_READ_EXTERNAL", "SUM_TIMER_WRITE_ALLOW_WRITE", "SUM_TIMER_WRITE_CONCURRENT_INSERT", "SUM_TIMER_WRITE_LOW_PRIORITY", "SUM_TIMER_WRITE_NORMAL", "SUM_TIMER_WRITE_EXTERNAL"}
+	rows := sqlmock.NewRows(columns).
+		// Note, timers are in picoseconds.
+		AddRow("shop", "orders", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11000000000000", "12000000000000", "13000000000000", "14000000000000", "15000000000000", "16000000000000", "17000000000000", "18000000000000", "19000000000000", "20000000000000")
+	mock.ExpectQuery(sanitizeQuery(perfTableLockWaitsQuery+"LIMIT 5")).WithArgs("^shop$", ".*").WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfTableLockWaits{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_normal"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_with_shared_locks"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_high_priority"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_no_insert"}, value: 4, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_normal"}, value: 9, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_allow_write"}, value: 6, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_concurrent_insert"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_low_priority"}, value: 8, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_normal"}, value: 11, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_with_shared_locks"}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_high_priority"}, value: 13, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read_no_insert"}, value: 14, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_normal"}, value: 19, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_allow_write"}, value: 16, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_concurrent_insert"}, value: 17, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write_low_priority"}, value: 18, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "read"}, value: 15, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "write"}, value: 20, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
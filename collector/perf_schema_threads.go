@@ -0,0 +1,135 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.threads`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	perfThreadsQuery = `
+	SELECT TYPE, IFNULL(PROCESSLIST_STATE, ''), COUNT(*), SUM(INSTRUMENTED = 'YES')
+	  FROM performance_schema.threads
+	  GROUP BY TYPE, PROCESSLIST_STATE
+	`
+	perfThreadsByUserQuery = `
+	SELECT TYPE, IFNULL(PROCESSLIST_STATE, ''), IFNULL(PROCESSLIST_USER, ''), COUNT(*), SUM(INSTRUMENTED = 'YES')
+	  FROM performance_schema.threads
+	  GROUP BY TYPE, PROCESSLIST_STATE, PROCESSLIST_USER
+	`
+)
+
+// Tunable flags.
+var (
+	perfThreadsByUser = kingpin.Flag(
+		"collect.perf_schema.threads.by_user",
+		"Additionally break down performance_schema.threads by processlist_user",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaThreadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "threads"),
+		"The number of server threads by type and processlist state.",
+		[]string{"type", "state"}, nil,
+	)
+	performanceSchemaThreadsByUserDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "threads_by_user"),
+		"The number of server threads by type, processlist state and user.",
+		[]string{"type", "state", "user"}, nil,
+	)
+	performanceSchemaThreadsInstrumentedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "threads_instrumented"),
+		"The number of server threads for which events are instrumented.",
+		nil, nil,
+	)
+)
+
+// ScrapeThreadsByType collects from `performance_schema.threads`.
+type ScrapeThreadsByType struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeThreadsByType) Name() string {
+	return "perf_schema.threads"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeThreadsByType) Help() string {
+	return "Collect thread counts by type and state from performance_schema.threads"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeThreadsByType) Version() float64 {
+	return 5.6
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeThreadsByType) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	query := perfThreadsQuery
+	if *perfThreadsByUser {
+		query = perfThreadsByUserQuery
+	}
+	threadsRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		return err
+	}
+	defer threadsRows.Close()
+
+	var (
+		threadType, state, user string
+		count, instrumented     uint64
+		instrumentedTotal       uint64
+	)
+	for threadsRows.Next() {
+		if *perfThreadsByUser {
+			if err := threadsRows.Scan(&threadType, &state, &user, &count, &instrumented); err != nil {
+				if skipScanError(ctx, err) {
+					continue
+				}
+				return err
+			}
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaThreadsByUserDesc, prometheus.GaugeValue, float64(count),
+				threadType, state, user,
+			)
+		} else {
+			if err := threadsRows.Scan(&threadType, &state, &count, &instrumented); err != nil {
+				if skipScanError(ctx, err) {
+					continue
+				}
+				return err
+			}
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaThreadsDesc, prometheus.GaugeValue, float64(count),
+				threadType, state,
+			)
+		}
+		instrumentedTotal += instrumented
+	}
+	if err := threadsRows.Err(); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(performanceSchemaThreadsInstrumentedDesc, prometheus.GaugeValue, float64(instrumentedTotal))
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeThreadsByType{}
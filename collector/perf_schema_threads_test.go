@@ -0,0 +1,124 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeThreadsByType(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TYPE", "PROCESSLIST_STATE", "COUNT(*)", "SUM(INSTRUMENTED = 'YES')"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("BACKGROUND", "", "40", "38").
+		AddRow("FOREGROUND", "", "12", "12").
+		AddRow("FOREGROUND", "Sending data", "3", "2")
+	mock.ExpectQuery(sanitizeQuery(perfThreadsQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeThreadsByType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"type": "BACKGROUND", "state": ""}, value: 40, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "FOREGROUND", "state": ""}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "FOREGROUND", "state": "Sending data"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 52, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapePerfThreadsByUser(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.threads.by_user",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TYPE", "PROCESSLIST_STATE", "PROCESSLIST_USER", "COUNT(*)", "SUM(INSTRUMENTED = 'YES')"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("BACKGROUND", "", "", "40", "40").
+		AddRow("FOREGROUND", "Sending data", "app", "2", "2").
+		AddRow("FOREGROUND", "Sending data", "report", "1", "0")
+	mock.ExpectQuery(sanitizeQuery(perfThreadsByUserQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeThreadsByType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"type": "BACKGROUND", "state": "", "user": ""}, value: 40, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "FOREGROUND", "state": "Sending data", "user": "app"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"type": "FOREGROUND", "state": "Sending data", "user": "report"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 42, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.variables_by_thread`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// perfVariablesByThreadQuery returns the session value of each allowlisted
+// variable along with its global value, the comparison is left to the
+// exporter so that values are compared the same way on all versions.
+const perfVariablesByThreadQuery = `
+	SELECT t.THREAD_ID, t.VARIABLE_NAME, IFNULL(t.VARIABLE_VALUE, ''), IFNULL(g.VARIABLE_VALUE, '')
+	  FROM performance_schema.variables_by_thread t
+	  JOIN performance_schema.global_variables g USING (VARIABLE_NAME)
+	  WHERE FIND_IN_SET(t.VARIABLE_NAME, ?) > 0
+	  ORDER BY t.THREAD_ID
+	`
+
+// Tunable flags.
+var (
+	perfVariablesByThreadVariables = kingpin.Flag(
+		"collect.perf_schema.variables_by_thread.variables",
+		"Comma separated list of variables to compare the session values of with the global values",
+	).Default("sql_mode,autocommit,transaction_isolation,max_execution_time,sort_buffer_size,join_buffer_size,read_buffer_size,read_rnd_buffer_size,tmp_table_size,max_heap_table_size").String()
+	perfVariablesByThreadByThread = kingpin.Flag(
+		"collect.perf_schema.variables_by_thread.by_thread",
+		"Additionally collect the number of overridden variables per thread",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaVariablesByThreadOverridesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "variables_by_thread_overrides"),
+		"The number of sessions whose value of the variable differs from the global value.",
+		[]string{"variable"}, nil,
+	)
+	performanceSchemaVariablesByThreadOverridesByThreadDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "variables_by_thread_overrides_by_thread"),
+		"The number of variables whose session value differs from the global value by thread.",
+		[]string{"thread_id"}, nil,
+	)
+)
+
+// ScrapeVariablesByThread collects from `performance_schema.variables_by_thread`.
+type ScrapeVariablesByThread struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeVariablesByThread) Name() string {
+	return "perf_schema.variables_by_thread"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeVariablesByThread) Help() string {
+	return "Collect the number of sessions overriding the global value of variables from performance_schema.variables_by_thread"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeVariablesByThread) Version() float64 {
+	return 8.0
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeVariablesByThread) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	// MariaDB reports versions above 8.0 but has no variables_by_thread table.
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMariaDB {
+		return nil
+	}
+	variables := parseVariablesByThreadList(*perfVariablesByThreadVariables)
+	if len(variables) == 0 {
+		return nil
+	}
+	variablesRows, err := queryContext(ctx, db, perfVariablesByThreadQuery, strings.Join(variables, ","))
+	if err != nil {
+		return err
+	}
+	defer variablesRows.Close()
+
+	var (
+		threadID                        uint64
+		name, sessionValue, globalValue string
+		lastThread                      uint64
+		threadOverrides                 int
+		seenThread                      bool
+	)
+	overrides := make(map[string]int, len(variables))
+	emitThread := func() {
+		if *perfVariablesByThreadByThread && seenThread {
+			ch <- prometheus.MustNewConstMetric(performanceSchemaVariablesByThreadOverridesByThreadDesc, prometheus.GaugeValue,
+				float64(threadOverrides), strconv.FormatUint(lastThread, 10))
+		}
+	}
+	for variablesRows.Next() {
+		if err := variablesRows.Scan(&threadID, &name, &sessionValue, &globalValue); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if !seenThread || threadID != lastThread {
+			emitThread()
+			lastThread, threadOverrides, seenThread = threadID, 0, true
+		}
+		if sessionValue != globalValue {
+			overrides[strings.ToLower(name)]++
+			threadOverrides++
+		}
+	}
+	if err := variablesRows.Err(); err != nil {
+		return err
+	}
+	emitThread()
+
+	// Variables without overrides are reported as 0, so that their series
+	// do not disappear between scrapes.
+	for _, variable := range variables {
+		ch <- prometheus.MustNewConstMetric(performanceSchemaVariablesByThreadOverridesDesc, prometheus.GaugeValue, float64(overrides[variable]), variable)
+	}
+	return nil
+}
+
+// parseVariablesByThreadList parses a comma separated list of variable names.
+func parseVariablesByThreadList(list string) []string {
+	var variables []string
+	for _, field := range strings.Split(list, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		variables = append(variables, field)
+	}
+	return variables
+}
+
+// check interface
+var _ Scraper = ScrapeVariablesByThread{}
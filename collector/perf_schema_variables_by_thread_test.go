@@ -0,0 +1,84 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeVariablesByThread(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.variables_by_thread.variables", "sql_mode, sort_buffer_size,autocommit",
+		"--collect.perf_schema.variables_by_thread.by_thread",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"THREAD_ID", "VARIABLE_NAME", "VARIABLE_VALUE", "VARIABLE_VALUE"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("47", "sort_buffer_size", "262144", "262144").
+		AddRow("47", "sql_mode", "STRICT_TRANS_TABLES", "STRICT_TRANS_TABLES").
+		AddRow("48", "sort_buffer_size", "268435456", "262144").
+		AddRow("48", "sql_mode", "", "STRICT_TRANS_TABLES").
+		AddRow("49", "sort_buffer_size", "67108864", "262144").
+		AddRow("49", "sql_mode", "STRICT_TRANS_TABLES", "STRICT_TRANS_TABLES")
+	mock.ExpectQuery(sanitizeQuery(perfVariablesByThreadQuery)).
+		WithArgs("sql_mode,sort_buffer_size,autocommit").
+		WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeVariablesByThread{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"thread_id": "47"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "48"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "49"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "sql_mode"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "sort_buffer_size"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"variable": "autocommit"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// MySQL error numbers of transient errors, after which the query may succeed.
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrLockDeadlock    = 1213
+)
+
+// retryTransientBackoff is the maximum wait before the first retry of a query,
+// doubled for each further retry up to a second.
+const retryTransientBackoff = 10 * time.Millisecond
+
+// Tunable flags.
+var (
+	retryTransient = kingpin.Flag(
+		"collect.retry_transient",
+		"Number of times to retry collector queries failing with a deadlock or lock wait timeout, 0 to not retry",
+	).Default("0").Int()
+	continueOnError = kingpin.Flag(
+		"collect.continue_on_error",
+		"Skip rows that fail to scan instead of failing the collector, counting them in mysql_exporter_scrape_errors_total",
+	).Default("false").Bool()
+)
+
+type queryCounterKey struct{}
+
+type targetKey struct{}
+
+type retriesKey struct{}
+
+type scanErrorsKey struct{}
+
+// scanErrors counts the skipped rows of a collector.
+type scanErrors struct {
+	collector string
+	counter   prometheus.Counter
+}
+
+// withQueryCounter returns a context counting the queries issued with
+// queryContext and queryRowContext in counter.
+func withQueryCounter(ctx context.Context, counter prometheus.Counter) context.Context {
+	return context.WithValue(ctx, queryCounterKey{}, counter)
+}
+
+// withTarget returns a context carrying the DSN of the scraped server, so
+// that scrapers keeping state between scrapes keep it per server.
+func withTarget(ctx context.Context, dsn string) context.Context {
+	return context.WithValue(ctx, targetKey{}, dsn)
+}
+
+// targetFromContext returns the DSN of the scraped server, or an empty string.
+func targetFromContext(ctx context.Context) string {
+	dsn, _ := ctx.Value(targetKey{}).(string)
+	return dsn
+}
+
+// withRetries returns a context retrying the queries issued with
+// queryContext up to retries times after a transient error.
+func withRetries(ctx context.Context, retries int) context.Context {
+	return context.WithValue(ctx, retriesKey{}, retries)
+}
+
+func countQuery(ctx context.Context) {
+	if counter, ok := ctx.Value(queryCounterKey{}).(prometheus.Counter); ok {
+		counter.Inc()
+	}
+}
+
+// withScanErrors returns a context counting the rows of collector skipped
+// by skipScanError in counter.
+func withScanErrors(ctx context.Context, collector string, counter prometheus.Counter) context.Context {
+	return context.WithValue(ctx, scanErrorsKey{}, scanErrors{collector: collector, counter: counter})
+}
+
+// skipScanError reports whether the row that failed to scan with err should
+// be skipped, so that the rows already collected are kept. With
+// --collect.continue_on_error the error is logged and counted instead.
+func skipScanError(ctx context.Context, err error) bool {
+	if !*continueOnError {
+		return false
+	}
+	errs, _ := ctx.Value(scanErrorsKey{}).(scanErrors)
+	if errs.counter != nil {
+		errs.counter.Inc()
+	}
+	log.With("collector", errs.collector).Errorln("Error scanning row:", err)
+	return true
+}
+
+// queryContext is db.QueryContext, counting the query. Scrapers use it for
+// all their queries so that the load of the exporter on MySQL is visible.
+// Queries failing with a transient error are retried up to the number of
+// times set with withRetries, after a random wait.
+func queryContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+	retries, _ := ctx.Value(retriesKey{}).(int)
+	for attempt := 0; ; attempt++ {
+		countQuery(ctx)
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err == nil || attempt >= retries || !isTransientError(err) {
+			return rows, err
+		}
+		backoff := retryTransientBackoff << uint(attempt)
+		if backoff <= 0 || backoff > time.Second {
+			backoff = time.Second
+		}
+		wait := time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// isTransientError reports whether err is a MySQL deadlock or lock wait
+// timeout error.
+func isTransientError(err error) bool {
+	mysqlErr, ok := err.(*mysqldriver.MySQLError)
+	return ok && (mysqlErr.Number == mysqlErrLockDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout)
+}
+
+// queryRowContext is db.QueryRowContext, counting the query. The error of a
+// row is only returned by Scan, so transient errors are not retried.
+func queryRowContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) *sql.Row {
+	countQuery(ctx)
+	return db.QueryRowContext(ctx, query, args...)
+}
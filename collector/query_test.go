@@ -0,0 +1,192 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestQueryCounter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The binlog_size collector checks log_bin before listing the binlogs.
+	mock.ExpectQuery(logbinQuery).WillReturnRows(sqlmock.NewRows([]string{""}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery(binlogQuery)).WillReturnRows(sqlmock.NewRows([]string{"Log_name", "File_size"}).AddRow("mysql-bin.000001", "120"))
+	mock.ExpectQuery(sanitizeQuery(perfSetupInstrumentsQuery)).WillReturnRows(sqlmock.NewRows([]string{"PREFIX", "ENABLED", "COUNT(*)"}))
+
+	counter := NewMetrics().Queries
+	ctx := withQueryCounter(context.Background(), counter)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		for _, scraper := range []Scraper{ScrapeBinlogSize{}, ScrapeSetupInstruments{}} {
+			if err := scraper.Scrape(ctx, db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+		}
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Queries are counted", t, func() {
+		m := &dto.Metric{}
+		convey.So(counter.Write(m), convey.ShouldBeNil)
+		convey.So(m.GetCounter().GetValue(), convey.ShouldEqual, 3)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestQueryContextRetryTransient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	deadlock := &mysqldriver.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"}
+	mock.ExpectQuery(sanitizeQuery(perfSessionStatusQuery)).WillReturnError(deadlock)
+	mock.ExpectQuery(sanitizeQuery(perfSessionStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"VARIABLE_NAME", "VARIABLE_VALUE"}).AddRow("Bytes_sent", "48213"))
+
+	counter := NewMetrics().Queries
+	ctx := withRetries(withQueryCounter(context.Background(), counter), 2)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSessionStatus{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Deadlocked queries are retried", t, func() {
+		got := readMetric(<-ch)
+		convey.So(got, convey.ShouldResemble, MetricResult{labels: labelMap{"variable": "bytes_sent"}, value: 48213, metricType: dto.MetricType_GAUGE})
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+
+		var m dto.Metric
+		convey.So(counter.Write(&m), convey.ShouldBeNil)
+		convey.So(m.GetCounter().GetValue(), convey.ShouldEqual, 2)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestQueryContextNoRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	lockWait := &mysqldriver.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded; try restarting transaction"}
+	noTable := &mysqldriver.MySQLError{Number: 1146, Message: "Table 'performance_schema.session_status' doesn't exist"}
+	mock.ExpectQuery("SELECT 1").WillReturnError(lockWait)
+	mock.ExpectQuery("SELECT 1").WillReturnError(lockWait)
+	mock.ExpectQuery("SELECT 1").WillReturnError(lockWait)
+	mock.ExpectQuery("SELECT 2").WillReturnError(noTable)
+
+	ctx := withRetries(context.Background(), 2)
+	convey.Convey("Retries are bounded", t, func() {
+		_, err := queryContext(ctx, db, "SELECT 1")
+		convey.So(err, convey.ShouldEqual, lockWait)
+	})
+	convey.Convey("Other errors are not retried", t, func() {
+		_, err := queryContext(ctx, db, "SELECT 2")
+		convey.So(err, convey.ShouldEqual, noTable)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestSkipScanError(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.continue_on_error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"SCHEMA_NAME", "SUM(COUNT_STAR)", "SUM(SUM_TIMER_WAIT)", "SUM(SUM_ERRORS)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "10", "2000000000000", "1").
+		AddRow("broken", "not a number", "0", "0").
+		AddRow("web", "5", "1000000000000", "0")
+	mock.ExpectQuery(sanitizeQuery(perfEventsStatementsBySchemaQuery)).WillReturnRows(rows)
+
+	counter := NewMetrics().ScrapeErrors.WithLabelValues("collect.perf_schema.eventsstatementsbyschema")
+	ctx := withScanErrors(context.Background(), "collect.perf_schema.eventsstatementsbyschema", counter)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSchemaStatementSummary{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"schema": "app"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "app"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "app"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "web"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "web"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "web"}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Rows around the broken row are collected", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Skipped rows are counted", t, func() {
+		m := &dto.Metric{}
+		convey.So(counter.Write(m), convey.ShouldBeNil)
+		convey.So(m.GetCounter().GetValue(), convey.ShouldEqual, 1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ScraperRegistry holds all known scrapers and whether they are enabled.
+// Scrapers can be enabled and disabled at runtime, the change applies to the
+// next scrape. It is safe for concurrent use.
+type ScraperRegistry struct {
+	mtx      sync.RWMutex
+	scrapers map[string]Scraper
+	enabled  map[string]bool
+}
+
+// NewScraperRegistry returns a registry of the given scrapers, mapped to
+// whether they are initially enabled.
+func NewScraperRegistry(scrapers map[Scraper]bool) *ScraperRegistry {
+	r := &ScraperRegistry{
+		scrapers: make(map[string]Scraper, len(scrapers)),
+		enabled:  make(map[string]bool, len(scrapers)),
+	}
+	for scraper, enabled := range scrapers {
+		r.scrapers[scraper.Name()] = scraper
+		r.enabled[scraper.Name()] = enabled
+	}
+	return r
+}
+
+// Enable enables the scraper with the given name.
+func (r *ScraperRegistry) Enable(name string) error {
+	return r.set(name, true)
+}
+
+// Disable disables the scraper with the given name.
+func (r *ScraperRegistry) Disable(name string) error {
+	return r.set(name, false)
+}
+
+func (r *ScraperRegistry) set(name string, enabled bool) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.scrapers[name]; !ok {
+		return fmt.Errorf("unknown collector %q", name)
+	}
+	r.enabled[name] = enabled
+	return nil
+}
+
+// Enabled returns the enabled scrapers ordered by name.
+func (r *ScraperRegistry) Enabled() []Scraper {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var scrapers []Scraper
+	for _, name := range r.names() {
+		if r.enabled[name] {
+			scrapers = append(scrapers, r.scrapers[name])
+		}
+	}
+	return scrapers
+}
+
+// Status returns whether each known scraper is enabled, by scraper name.
+func (r *ScraperRegistry) Status() map[string]bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	status := make(map[string]bool, len(r.enabled))
+	for name, enabled := range r.enabled {
+		status[name] = enabled
+	}
+	return status
+}
+
+func (r *ScraperRegistry) names() []string {
+	names := make([]string, 0, len(r.scrapers))
+	for name := range r.scrapers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
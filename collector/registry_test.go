@@ -0,0 +1,77 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScraperRegistry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	registry := NewScraperRegistry(map[Scraper]bool{
+		stubScraper{name: "a"}: true,
+		stubScraper{name: "b"}: false,
+	})
+
+	// scraped returns the collectors that reported a success metric.
+	scraped := func() []string {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow("5.7.26", "MySQL Community Server (GPL)"))
+		exporter := New(context.Background(), dsn, NewMetrics(), registry.Enabled())
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.scrapeAll(context.Background(), db, ch)
+			close(ch)
+		}()
+		var names []string
+		for m := range ch {
+			if strings.Contains(m.Desc().String(), "collector_success") {
+				names = append(names, readMetric(m).labels["collector"])
+			}
+		}
+		return names
+	}
+
+	convey.Convey("Collectors are toggled at runtime", t, func() {
+		convey.So(scraped(), convey.ShouldResemble, []string{"collect.a"})
+
+		convey.So(registry.Enable("b"), convey.ShouldBeNil)
+		convey.So(registry.Disable("a"), convey.ShouldBeNil)
+		convey.So(scraped(), convey.ShouldResemble, []string{"collect.b"})
+		convey.So(registry.Status(), convey.ShouldResemble, map[string]bool{"a": false, "b": true})
+
+		convey.So(registry.Disable("b"), convey.ShouldBeNil)
+		convey.So(scraped(), convey.ShouldBeEmpty)
+	})
+
+	convey.Convey("Unknown collectors are rejected", t, func() {
+		convey.So(registry.Enable("c"), convey.ShouldBeError, `unknown collector "c"`)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
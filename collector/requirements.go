@@ -0,0 +1,103 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Skip collectors whose server features are not available.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/common/log"
+)
+
+// Server features a scraper may require.
+const (
+	FeaturePerformanceSchema = "performance_schema"
+	FeatureSysSchema         = "sys_schema"
+)
+
+// serverFeaturesQuery reports whether performance_schema is enabled and
+// whether the sys schema is installed.
+const serverFeaturesQuery = `
+	SELECT @@performance_schema,
+	       (SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = ?)
+	`
+
+// Requirer is implemented by scrapers that only make sense with some server
+// features available. Scrapers not implementing it require nothing.
+type Requirer interface {
+	// Requires names the server features needed by the scraper, e.g.
+	// FeaturePerformanceSchema.
+	Requires() []string
+}
+
+// serverFeatures holds the features available on a server.
+type serverFeatures map[string]bool
+
+// missing returns the first of the features required by scraper that is not
+// available, or an empty string if all are.
+func (f serverFeatures) missing(scraper Scraper) string {
+	r, ok := scraper.(Requirer)
+	if !ok || f == nil {
+		return ""
+	}
+	for _, feature := range r.Requires() {
+		if !f[feature] {
+			return feature
+		}
+	}
+	return ""
+}
+
+// featuresCache holds the features detected for each DSN, they are only
+// checked on the first scrape of a server.
+var featuresCache = struct {
+	sync.Mutex
+	features map[string]serverFeatures
+}{features: map[string]serverFeatures{}}
+
+// needsFeatures reports whether any of the scrapers has requirements.
+func needsFeatures(scrapers []Scraper) bool {
+	for _, scraper := range scrapers {
+		if _, ok := scraper.(Requirer); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getServerFeatures returns the features of the server behind dsn, detecting
+// them with db on the first call. It returns nil, requiring nothing, if the
+// detection failed.
+func getServerFeatures(ctx context.Context, dsn string, db *sql.DB) serverFeatures {
+	featuresCache.Lock()
+	defer featuresCache.Unlock()
+	if features, ok := featuresCache.features[dsn]; ok {
+		return features
+	}
+	var performanceSchema bool
+	var sysSchemas int
+	if err := queryRowContext(ctx, db, serverFeaturesQuery, *sysSchemaName).Scan(&performanceSchema, &sysSchemas); err != nil {
+		log.Warnln("Error detecting server features, collector requirements are not checked:", err)
+		return nil
+	}
+	features := serverFeatures{
+		FeaturePerformanceSchema: performanceSchema,
+		FeatureSysSchema:         sysSchemas > 0,
+	}
+	featuresCache.features[dsn] = features
+	return features
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// requiringScraper is a stubScraper requiring server features.
+type requiringScraper struct {
+	stubScraper
+	requires []string
+}
+
+func (s requiringScraper) Requires() []string { return s.requires }
+
+func TestScrapeAllRequirementSkipped(t *testing.T) {
+	const featuresDSN = "root@/requirements"
+	defer func() {
+		featuresCache.Lock()
+		delete(featuresCache.features, featuresDSN)
+		featuresCache.Unlock()
+	}()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	exporter := &Exporter{dsn: featuresDSN, metrics: NewMetrics(), scrapers: []Scraper{
+		stubScraper{name: "plain"},
+		requiringScraper{stubScraper: stubScraper{name: "perf"}, requires: []string{FeaturePerformanceSchema}},
+		ScrapeSysUserSummaryByStatementType{},
+	}}
+
+	// The features are only detected on the first scrape.
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow("8.0.33", "MySQL Community Server - GPL"))
+		if i == 0 {
+			mock.ExpectQuery(sanitizeQuery(serverFeaturesQuery)).WithArgs("sys").
+				WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema", "COUNT(*)"}).AddRow(1, 0))
+		}
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.scrapeAll(context.Background(), db, ch)
+			close(ch)
+		}()
+
+		skipped := map[string]string{}
+		scraped := map[string]bool{}
+		for m := range ch {
+			got := readMetric(m)
+			switch {
+			case strings.Contains(m.Desc().String(), "collector_requirement_skipped"):
+				skipped[got.labels["collector"]] = got.labels["requirement"]
+			case strings.Contains(m.Desc().String(), "collector_success"):
+				scraped[got.labels["collector"]] = true
+			}
+		}
+
+		convey.Convey("Collectors with unmet requirements are reported as skipped", t, func() {
+			convey.So(skipped, convey.ShouldResemble, map[string]string{"collect.sys.user_summary_by_statement_type": FeatureSysSchema})
+			convey.So(scraped, convey.ShouldResemble, map[string]bool{"collect.plain": true, "collect.perf": true})
+		})
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
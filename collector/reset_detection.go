@@ -0,0 +1,154 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Detect counter resets between scrapes.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/log"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const serverUUIDQuery = `SELECT @@server_uuid`
+
+// Tunable flags.
+var (
+	resetDetection = kingpin.Flag(
+		"collect.reset_detection",
+		"Detect counters decreasing between scrapes, e.g. after a server restart, and report it in mysql_up_since_reset.",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	upSinceResetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "up_since_reset"),
+		"Whether the counters of the collector only increased since the previous scrape (0 when a reset was detected).",
+		[]string{"collector"}, nil,
+	)
+)
+
+// counterCache holds the last value seen for each counter.
+type counterCache struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// observe stores the value of the counter and reports whether it decreased.
+func (c *counterCache) observe(key string, value float64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last, ok := c.values[key]
+	c.values[key] = value
+	return ok && value < last
+}
+
+// prune removes the counters starting with prefix that are not in seen, so
+// that counters of dropped series do not accumulate.
+func (c *counterCache) prune(prefix string, seen map[string]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.values {
+		if _, ok := seen[key]; !ok && strings.HasPrefix(key, prefix) {
+			delete(c.values, key)
+		}
+	}
+}
+
+// lastCounters is shared by all scrapes, counters are keyed by server UUID,
+// or target DSN when the server has none, and collector.
+var lastCounters = &counterCache{values: map[string]float64{}}
+
+// resetTracker records the counters of one collector during a scrape.
+type resetTracker struct {
+	cache     *counterCache
+	prefix    string
+	collector string
+	seen      map[string]struct{}
+	reset     bool
+}
+
+// newResetTracker returns a tracker for the collector, nil when reset
+// detection is disabled. All methods are no-ops on a nil tracker.
+func newResetTracker(ctx context.Context, collector string) *resetTracker {
+	if !*resetDetection {
+		return nil
+	}
+	server, _ := serverUUIDFromContext(ctx)
+	if server == "" {
+		// Servers without @@server_uuid are told apart by their target.
+		server = targetFromContext(ctx)
+	}
+	return &resetTracker{
+		cache:     lastCounters,
+		prefix:    server + "/" + collector + "/",
+		collector: collector,
+		seen:      map[string]struct{}{},
+	}
+}
+
+// observe records the current value of the counter identified by key.
+func (t *resetTracker) observe(key string, value float64) {
+	if t == nil {
+		return
+	}
+	t.seen[t.prefix+key] = struct{}{}
+	if t.cache.observe(t.prefix+key, value) {
+		t.reset = true
+	}
+}
+
+// collect reports whether a reset was detected during the scrape, and
+// forgets the counters of the collector not observed during the scrape.
+func (t *resetTracker) collect(ch chan<- prometheus.Metric) {
+	if t == nil {
+		return
+	}
+	t.cache.prune(t.prefix, t.seen)
+	up := 1.0
+	if t.reset {
+		log.Infof("%s: counters decreased since the previous scrape, assuming a server restart", t.collector)
+		up = 0
+	}
+	ch <- prometheus.MustNewConstMetric(upSinceResetDesc, prometheus.GaugeValue, up, t.collector)
+}
+
+type serverUUIDKey struct{}
+
+// getServerUUID returns @@server_uuid, or an empty string where it is not
+// supported.
+func getServerUUID(ctx context.Context, db *sql.DB) string {
+	var uuid string
+	if err := queryRowContext(ctx, db, serverUUIDQuery).Scan(&uuid); err != nil {
+		return ""
+	}
+	return uuid
+}
+
+// withServerUUID returns a context carrying the server UUID.
+func withServerUUID(ctx context.Context, uuid string) context.Context {
+	return context.WithValue(ctx, serverUUIDKey{}, uuid)
+}
+
+// serverUUIDFromContext returns the server UUID detected for the scrape, if any.
+func serverUUIDFromContext(ctx context.Context) (string, bool) {
+	uuid, ok := ctx.Value(serverUUIDKey{}).(string)
+	return uuid, ok
+}
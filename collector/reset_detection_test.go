@@ -0,0 +1,147 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestResetTrackerDisabled(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("Reset detection is opt-in", t, func() {
+		convey.So(newResetTracker(context.Background(), "global_status"), convey.ShouldBeNil)
+	})
+}
+
+func TestScrapeSysUserSummaryResetDetection(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.reset_detection"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	ctx := withServerUUID(context.Background(), "3e11fa47-71ca-11e1-9e33-c80aa9429562")
+	columns := []string{"user", "statements"}
+
+	// The statements counter drops between the first and second scrape.
+	var signals []float64
+	for _, statements := range []string{"10", "5", "6"} {
+		rows := sqlmock.NewRows(columns).AddRow("app", statements)
+		mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeSysUserSummary{}).Scrape(ctx, db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		for m := range ch {
+			if strings.Contains(m.Desc().String(), "up_since_reset") {
+				got := readMetric(m)
+				convey.Convey("Reset signal labels", t, func() {
+					convey.So(got.labels, convey.ShouldResemble, labelMap{"collector": "sys.user_summary"})
+					convey.So(got.metricType, convey.ShouldEqual, dto.MetricType_GAUGE)
+				})
+				signals = append(signals, got.value)
+			}
+		}
+	}
+
+	convey.Convey("Reset is signalled once", t, func() {
+		convey.So(signals, convey.ShouldResemble, []float64{1, 0, 1})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestResetTrackerPrune(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.reset_detection"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	cache := &counterCache{values: map[string]float64{"other/global_status/a": 1}}
+	scrape := func(keys ...string) {
+		tracker := newResetTracker(context.Background(), "global_status")
+		tracker.cache = cache
+		for _, key := range keys {
+			tracker.observe(key, 1)
+		}
+		tracker.collect(make(chan prometheus.Metric, 1))
+	}
+
+	convey.Convey("Counters not seen in the latest scrape are forgotten", t, func() {
+		scrape("a", "b")
+		convey.So(cache.values, convey.ShouldResemble, map[string]float64{
+			"other/global_status/a": 1, "/global_status/a": 1, "/global_status/b": 1,
+		})
+		scrape("b")
+		convey.So(cache.values, convey.ShouldResemble, map[string]float64{
+			"other/global_status/a": 1, "/global_status/b": 1,
+		})
+	})
+}
+
+func TestResetTrackerTargetsWithoutUUID(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.reset_detection"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	cache := &counterCache{values: map[string]float64{}}
+	scrape := func(target string, value float64) bool {
+		tracker := newResetTracker(withTarget(context.Background(), target), "global_status")
+		tracker.cache = cache
+		tracker.observe("queries", value)
+		return tracker.reset
+	}
+
+	convey.Convey("Targets without server UUID keep their own counters", t, func() {
+		// The second target has fewer queries than the first one.
+		var resets []bool
+		for _, value := range []float64{100, 101} {
+			resets = append(resets, scrape("root@tcp(a:3306)/", value), scrape("root@tcp(b:3306)/", value-90))
+		}
+		convey.So(resets, convey.ShouldResemble, []bool{false, false, false, false})
+		convey.So(cache.values, convey.ShouldResemble, map[string]float64{
+			"root@tcp(a:3306)//global_status/queries": 101, "root@tcp(b:3306)//global_status/queries": 11,
+		})
+		convey.So(scrape("root@tcp(b:3306)/", 1), convey.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Run expensive collectors only on some scrapes.
+
+package collector
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sampled is implemented by scrapers that are expensive enough to only run
+// once every few scrapes. Scrapers not implementing it run on every scrape.
+type Sampled interface {
+	// IntervalFactor is the number of scrapes the scraper runs once in, 1 to
+	// run on every scrape.
+	IntervalFactor() int
+}
+
+// sampledScrape holds the number of scrapes of a collector and the metrics
+// sent by its last run.
+type sampledScrape struct {
+	count   int
+	metrics []prometheus.Metric
+}
+
+// sampledScrapes holds the sampled collectors of each DSN, keyed by DSN and
+// collector name.
+var sampledScrapes = struct {
+	sync.Mutex
+	scrapes map[string]*sampledScrape
+}{scrapes: map[string]*sampledScrape{}}
+
+// intervalFactor returns the interval factor of scraper, 1 if it is not
+// sampled.
+func intervalFactor(scraper Scraper) int {
+	if s, ok := scraper.(Sampled); ok && s.IntervalFactor() > 1 {
+		return s.IntervalFactor()
+	}
+	return 1
+}
+
+// cachedScrape counts a scrape of the collector identified by key and
+// returns the metrics of its last run if it is not its turn to run.
+func cachedScrape(key string, factor int) ([]prometheus.Metric, bool) {
+	sampledScrapes.Lock()
+	defer sampledScrapes.Unlock()
+	s, ok := sampledScrapes.scrapes[key]
+	if !ok {
+		s = &sampledScrape{}
+		sampledScrapes.scrapes[key] = s
+	}
+	s.count++
+	if (s.count-1)%factor == 0 {
+		return nil, false
+	}
+	return s.metrics, true
+}
+
+// storeScrape stores the metrics sent by the last run of the collector
+// identified by key.
+func storeScrape(key string, metrics []prometheus.Metric) {
+	sampledScrapes.Lock()
+	defer sampledScrapes.Unlock()
+	if s, ok := sampledScrapes.scrapes[key]; ok {
+		s.metrics = metrics
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// sampledScraper counts its runs and sends the run number as a metric.
+type sampledScraper struct {
+	stubScraper
+	factor int
+	runs   *int
+}
+
+var sampledScraperDesc = prometheus.NewDesc("sampled_run", "The run of the sampled scraper.", nil, nil)
+
+func (s sampledScraper) IntervalFactor() int { return s.factor }
+func (s sampledScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	*s.runs++
+	ch <- prometheus.MustNewConstMetric(sampledScraperDesc, prometheus.GaugeValue, float64(*s.runs))
+	return nil
+}
+
+func TestScrapeOneSampled(t *testing.T) {
+	const sampledDSN = "root@/sampling"
+	defer func() {
+		sampledScrapes.Lock()
+		delete(sampledScrapes.scrapes, sampledDSN+"/sampled")
+		sampledScrapes.Unlock()
+	}()
+
+	var runs int
+	scraper := sampledScraper{stubScraper: stubScraper{name: "sampled"}, factor: 3, runs: &runs}
+	exporter := &Exporter{dsn: sampledDSN, metrics: NewMetrics()}
+
+	var sent []float64
+	var successes int
+	for i := 1; i <= 7; i++ {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.scrapeOne(context.Background(), nil, scraper, ch)
+			close(ch)
+		}()
+		for m := range ch {
+			switch {
+			case m.Desc() == sampledScraperDesc:
+				sent = append(sent, readMetric(m).value)
+			case strings.Contains(m.Desc().String(), "collector_success"):
+				successes++
+			}
+		}
+	}
+
+	convey.Convey("Sampled collectors only run every interval factor scrapes", t, func() {
+		convey.So(runs, convey.ShouldEqual, 3)
+		// Skipped scrapes send the metrics of the last run again.
+		convey.So(sent, convey.ShouldResemble, []float64{1, 1, 1, 2, 2, 2, 3})
+		convey.So(successes, convey.ShouldEqual, 7)
+	})
+}
+
+func TestSysUserSummaryIntervalFactor(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.sys.user_summary.interval_factor", "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	convey.Convey("sys.user_summary is sampled by its flag", t, func() {
+		convey.So(intervalFactor(ScrapeSysUserSummary{}), convey.ShouldEqual, 5)
+		convey.So(intervalFactor(stubScraper{name: "plain"}), convey.ShouldEqual, 1)
+	})
+}
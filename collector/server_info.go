@@ -0,0 +1,85 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the version and uptime of the server.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const serverUptimeQuery = `SHOW GLOBAL STATUS LIKE 'Uptime'`
+
+// Metric descriptors.
+var (
+	// mysql_version_info is already emitted by the global_variables
+	// collector with other labels, hence the server subsystem.
+	serverVersionInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "server", "version_info"),
+		"The version, flavor and version comment of the server, always 1.",
+		[]string{"version", "flavor", "comment"}, nil,
+	)
+	serverUptimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "uptime_seconds"),
+		"The number of seconds since the server started.",
+		nil, nil,
+	)
+)
+
+// ScrapeServerInfo collects the version and uptime of the server.
+type ScrapeServerInfo struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeServerInfo) Name() string {
+	return "server_info"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeServerInfo) Help() string {
+	return "Collect the version, flavor and uptime of the server"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeServerInfo) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeServerInfo) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	v, ok := serverVersionFromContext(ctx)
+	if !ok {
+		var version, comment string
+		if err := queryRowContext(ctx, db, versionQuery).Scan(&version, &comment); err != nil {
+			return err
+		}
+		v = parseServerVersion(version, comment)
+	}
+	ch <- prometheus.MustNewConstMetric(serverVersionInfoDesc, prometheus.GaugeValue, 1, v.release(), v.Flavor, v.Comment)
+
+	var (
+		name   string
+		uptime float64
+	)
+	if err := queryRowContext(ctx, db, serverUptimeQuery).Scan(&name, &uptime); err != nil {
+		return err
+	}
+	ch <- prometheus.MustNewConstMetric(serverUptimeDesc, prometheus.GaugeValue, uptime)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeServerInfo{}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeServerInfo(t *testing.T) {
+	for _, tt := range []struct {
+		version, comment string
+		expected         labelMap
+	}{
+		{"8.0.33", "MySQL Community Server - GPL", labelMap{"version": "8.0.33", "flavor": "mysql", "comment": "MySQL Community Server - GPL"}},
+		{"5.5.5-10.6.12-MariaDB-log", "MariaDB Server", labelMap{"version": "10.6.12", "flavor": "mariadb", "comment": "MariaDB Server"}},
+		{"5.7.42-46-log", "Percona Server (GPL), Release 46, Revision e1f7e9c", labelMap{"version": "5.7.42", "flavor": "percona", "comment": "Percona Server (GPL), Release 46, Revision e1f7e9c"}},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"@@version", "@@version_comment"}).AddRow(tt.version, tt.comment))
+		mock.ExpectQuery(sanitizeQuery(serverUptimeQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "86400"))
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeServerInfo{}).Scrape(context.Background(), db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		metricExpected := []MetricResult{
+			{labels: tt.expected, value: 1, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 86400, metricType: dto.MetricType_GAUGE},
+		}
+		convey.Convey("Metrics comparison for "+tt.version, t, func() {
+			for _, expect := range metricExpected {
+				got := readMetric(<-ch)
+				convey.So(got, convey.ShouldResemble, expect)
+			}
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
@@ -31,6 +31,8 @@ const (
 	// The second column allows gets the server timestamp at the exact same
 	// time the query is run.
 	slaveHostsQuery = "SHOW SLAVE HOSTS"
+	// replicasQuery replaces SHOW SLAVE HOSTS as of MySQL 8.0.22.
+	replicasQuery = "SHOW REPLICAS"
 )
 
 // Metric descriptors.
@@ -40,6 +42,11 @@ var (
 		"Information about running slaves",
 		[]string{"server_id", "slave_host", "port", "master_id", "slave_uuid"}, nil,
 	)
+	SlaveHostsCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slavehosts, "count"),
+		"Number of slaves registered with the master.",
+		nil, nil,
+	)
 )
 
 // ScrapeSlaveHosts scrapes metrics about the replicating slaves.
@@ -62,7 +69,11 @@ func (ScrapeSlaveHosts) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
-	slaveHostsRows, err := db.QueryContext(ctx, slaveHostsQuery)
+	query := slaveHostsQuery
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor != flavorMariaDB && v.atLeast(8, 0, 22) {
+		query = replicasQuery
+	}
+	slaveHostsRows, err := queryContext(ctx, db, query)
 	if err != nil {
 		return err
 	}
@@ -79,13 +90,19 @@ func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 	var masterId string
 	var slaveUuid string
 
+	var count int
 	for slaveHostsRows.Next() {
 		// Newer versions of mysql have the following
 		// 		Server_id, Host, Port, Master_id, Slave_UUID
+		// SHOW REPLICAS returns the same columns as
+		// 		Server_Id, Host, Port, Source_Id, Replica_UUID
 		// Older versions of mysql have the following
 		// 		Server_id, Host, Port, Rpl_recovery_rank, Master_id
 		err := slaveHostsRows.Scan(&serverId, &host, &port, &rrrOrMasterId, &slaveUuidOrMasterId)
 		if err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
@@ -111,8 +128,13 @@ func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 			masterId,
 			slaveUuid,
 		)
+		count++
+	}
+	if err := slaveHostsRows.Err(); err != nil {
+		return err
 	}
 
+	ch <- prometheus.MustNewConstMetric(SlaveHostsCount, prometheus.GaugeValue, float64(count))
 	return nil
 }
 
@@ -47,6 +47,7 @@ func TestScrapeSlaveHostsOldFormat(t *testing.T) {
 	counterExpected := []MetricResult{
 		{labels: labelMap{"server_id": "380239978", "slave_host": "backup_server_1", "port": "0", "master_id": "192168011", "slave_uuid": ""}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"server_id": "11882498", "slave_host": "backup_server_2", "port": "0", "master_id": "192168011", "slave_uuid": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range counterExpected {
@@ -85,6 +86,7 @@ func TestScrapeSlaveHostsNewFormat(t *testing.T) {
 	counterExpected := []MetricResult{
 		{labels: labelMap{"server_id": "192168010", "slave_host": "iconnect2", "port": "3306", "master_id": "192168011", "slave_uuid": "14cb6624-7f93-11e0-b2c0-c80aa9429562"}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"server_id": "1921680101", "slave_host": "athena", "port": "3306", "master_id": "192168011", "slave_uuid": "07af4990-f41f-11df-a566-7ac56fdaf645"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range counterExpected {
@@ -98,3 +100,81 @@ func TestScrapeSlaveHostsNewFormat(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeSlaveHostsStatement(t *testing.T) {
+	for _, tt := range []struct {
+		version, comment string
+		query            string
+	}{
+		{"8.0.21", "MySQL Community Server - GPL", slaveHostsQuery},
+		{"8.0.22", "MySQL Community Server - GPL", replicasQuery},
+		{"8.0.33-25", "Percona Server (GPL), Release 25", replicasQuery},
+		{"10.11.2-MariaDB", "mariadb.org binary distribution", slaveHostsQuery},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		columns := []string{"Server_Id", "Host", "Port", "Source_Id", "Replica_UUID"}
+		rows := sqlmock.NewRows(columns).
+			AddRow("192168010", "iconnect2", "3306", "192168011", "14cb6624-7f93-11e0-b2c0-c80aa9429562")
+		mock.ExpectQuery("^" + sanitizeQuery(tt.query) + "$").WillReturnRows(rows)
+
+		ctx := withServerVersion(context.Background(), parseServerVersion(tt.version, tt.comment))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeSlaveHosts{}).Scrape(ctx, db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		counterExpected := []MetricResult{
+			{labels: labelMap{"server_id": "192168010", "slave_host": "iconnect2", "port": "3306", "master_id": "192168011", "slave_uuid": "14cb6624-7f93-11e0-b2c0-c80aa9429562"}, value: 1, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		}
+		convey.Convey("Metrics comparison for "+tt.version, t, func() {
+			for _, expect := range counterExpected {
+				got := readMetric(<-ch)
+				convey.So(got, convey.ShouldResemble, expect)
+			}
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestScrapeSlaveHostsNoReplicas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Server_id", "Host", "Port", "Master_id", "Slave_UUID"}
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE HOSTS")).WillReturnRows(sqlmock.NewRows(columns))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveHosts{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Only the count is reported", t, func() {
+		convey.So(readMetric(<-ch), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE})
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -75,11 +75,11 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 	)
 	// Try the both syntax for MySQL/Percona and MariaDB
 	for _, query := range slaveStatusQueries {
-		slaveStatusRows, err = db.QueryContext(ctx, query)
+		slaveStatusRows, err = queryContext(ctx, db, query)
 		if err != nil { // MySQL/Percona
 			// Leverage lock-free SHOW SLAVE STATUS by guessing the right suffix
 			for _, suffix := range slaveStatusQuerySuffixes {
-				slaveStatusRows, err = db.QueryContext(ctx, fmt.Sprint(query, suffix))
+				slaveStatusRows, err = queryContext(ctx, db, fmt.Sprint(query, suffix))
 				if err == nil {
 					break
 				}
@@ -108,6 +108,9 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		}
 
 		if err := slaveStatusRows.Scan(scanArgs...); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
 			return err
 		}
 
@@ -132,7 +135,7 @@ func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 			}
 		}
 	}
-	return nil
+	return slaveStatusRows.Err()
 }
 
 // check interface
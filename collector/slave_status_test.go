@@ -61,3 +61,51 @@ func TestScrapeSlaveStatus(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeSlaveStatusChannels(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Slave_IO_Running", "Slave_SQL_Running", "Seconds_Behind_Master", "Relay_Log_Space", "Master_UUID", "Channel_Name"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("10.0.0.1", "Yes", "Yes", "3", "4096", "3e11fa47-71ca-11e1-9e33-c80aa9429562", "orders").
+		AddRow("10.0.0.2", "Connecting", "No", nil, "1024", "74d2e1e4-71ca-11e1-9e33-c80aa9429562", "billing")
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveStatus{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	orders := labelMap{"channel_name": "orders", "connection_name": "", "master_host": "10.0.0.1", "master_uuid": "3e11fa47-71ca-11e1-9e33-c80aa9429562"}
+	billing := labelMap{"channel_name": "billing", "connection_name": "", "master_host": "10.0.0.2", "master_uuid": "74d2e1e4-71ca-11e1-9e33-c80aa9429562"}
+	metricExpected := []MetricResult{
+		{labels: orders, value: 1, metricType: dto.MetricType_UNTYPED},
+		{labels: orders, value: 1, metricType: dto.MetricType_UNTYPED},
+		{labels: orders, value: 3, metricType: dto.MetricType_UNTYPED},
+		{labels: orders, value: 4096, metricType: dto.MetricType_UNTYPED},
+		// A NULL Seconds_Behind_Master is skipped.
+		{labels: billing, value: 0, metricType: dto.MetricType_UNTYPED},
+		{labels: billing, value: 0, metricType: dto.MetricType_UNTYPED},
+		{labels: billing, value: 1024, metricType: dto.MetricType_UNTYPED},
+	}
+	convey.Convey("Metrics are labelled by channel", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/common/log"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// ER_NO_SUCH_TABLE, returned when the sys schema is not installed.
+const mysqlErrNoSuchTable = 1146
+
+// Subsystem.
+const sysSchema = "sys"
+
+// Tunable flags.
+var (
+	sysSchemaName = kingpin.Flag(
+		"collect.sys.schema",
+		"Name of the schema the sys objects are installed in",
+	).Default(sysSchema).String()
+	sysMaxExecutionTime = kingpin.Flag(
+		"collect.sys.max_execution_time_ms",
+		"Abort sys queries running longer than this many milliseconds on the server, 0 to disable",
+	).Default("0").Int()
+)
+
+// sysSchemaMissingWarned records the collectors that already logged a missing
+// sys schema, so the warning isn't repeated on every scrape.
+var sysSchemaMissingWarned sync.Map
+
+// contextDone returns the context error once ctx is cancelled or its deadline
+// is exceeded, so that long result sets stop being consumed after the scrape
+// timeout.
+func contextDone(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// sysSchemaSupported reports whether the server is expected to ship the sys
+// schema. MariaDB only bundles it since 10.6.
+func sysSchemaSupported(ctx context.Context) bool {
+	v, ok := serverVersionFromContext(ctx)
+	if !ok || v.Flavor != flavorMariaDB {
+		return true
+	}
+	return v.atLeast(10, 6, 0)
+}
+
+// isTableMissing reports whether err is a MySQL "table doesn't exist" error.
+func isTableMissing(err error) bool {
+	mysqlErr, ok := err.(*mysqldriver.MySQLError)
+	return ok && mysqlErr.Number == mysqlErrNoSuchTable
+}
+
+// warnSysSchemaMissing logs once per collector that the sys views it reads
+// are absent.
+func warnSysSchemaMissing(collector string, err error) {
+	if _, warned := sysSchemaMissingWarned.LoadOrStore(collector, true); !warned {
+		log.Warnf("%s: sys schema is not installed, skipping collector: %s", collector, err)
+	}
+}
+
+// withMaxExecutionTime adds a MAX_EXECUTION_TIME optimizer hint to the
+// SELECT, so that the server itself aborts expensive sys queries instead of
+// leaving them running after the scrape timed out.
+func withMaxExecutionTime(query string) string {
+	if *sysMaxExecutionTime <= 0 {
+		return query
+	}
+	hint := fmt.Sprintf("SELECT /*+ MAX_EXECUTION_TIME(%d) */", *sysMaxExecutionTime)
+	return strings.Replace(query, "SELECT", hint, 1)
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$host_summary_by_file_io_type`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const sysHostSummaryByFileIOQuery = `
+	SELECT
+	    host,
+	    event_name,
+	    total,
+	    total_latency
+	  FROM ` + "`%s`.`x$host_summary_by_file_io_type`" + `
+	`
+
+// Metric descriptors.
+var (
+	sysHostSummaryFileIOs = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "host_file_ios_total"),
+		"The total number of file I/O events for the host and event name.",
+		[]string{"host", "event_name"}, nil,
+	)
+	sysHostSummaryFileIOLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "host_file_io_latency_seconds_total"),
+		"The total wait time of timed file I/O events for the host and event name in seconds.",
+		[]string{"host", "event_name"}, nil,
+	)
+)
+
+// ScrapeSysHostSummaryByFileIO collects from `sys.x$host_summary_by_file_io_type`.
+type ScrapeSysHostSummaryByFileIO struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSysHostSummaryByFileIO) Name() string {
+	return sysSchema + ".host_summary_by_file_io"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSysHostSummaryByFileIO) Help() string {
+	return "Collect per host file I/O metrics from sys.x$host_summary_by_file_io_type. See https://dev.mysql.com/doc/refman/5.7/en/sys-host-summary-by-file-io-type.html for details"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSysHostSummaryByFileIO) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSysHostSummaryByFileIO) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysHostSummaryByFileIOQuery, *sysSchemaName))
+	hostSummaryRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSysHostSummaryByFileIO{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer hostSummaryRows.Close()
+
+	var (
+		host      sql.NullString
+		eventName string
+		ios       uint64
+		ioLatency uint64
+	)
+
+	for hostSummaryRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := hostSummaryRows.Scan(&host, &eventName, &ios, &ioLatency); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		// Background threads are reported without a host.
+		hostLabel := "background"
+		if host.Valid {
+			hostLabel = host.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(sysHostSummaryFileIOs, prometheus.CounterValue, float64(ios), hostLabel, eventName)
+		newConstMetricFromSeconds(ch, sysHostSummaryFileIOLatency, prometheus.CounterValue, ioLatency, hostLabel, eventName)
+	}
+	return hostSummaryRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSysHostSummaryByFileIO{}
@@ -0,0 +1,72 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeSysHostSummaryByFileIO(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"host", "event_name", "total", "total_latency"}
+	rows := sqlmock.NewRows(columns).
+		// Note, latencies are in picoseconds.
+		AddRow("10.0.0.1", "wait/io/file/innodb/innodb_data_file", "100", "2500000000000").
+		AddRow(nil, "wait/io/file/sql/binlog", "7", "500000000000")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysHostSummaryByFileIOQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysHostSummaryByFileIO{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"host": "10.0.0.1", "event_name": "wait/io/file/innodb/innodb_data_file"}, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"host": "10.0.0.1", "event_name": "wait/io/file/innodb/innodb_data_file"}, value: 2.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"host": "background", "event_name": "wait/io/file/sql/binlog"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"host": "background", "event_name": "wait/io/file/sql/binlog"}, value: 0.5, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
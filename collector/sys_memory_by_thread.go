@@ -0,0 +1,219 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$memory_by_thread_by_current_bytes`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	// Threads are aggregated per user by default to bound cardinality.
+	sysMemoryByUserQuery = `
+	SELECT
+	    user,
+	    SUM(current_count_used),
+	    SUM(current_allocated),
+	    MAX(current_max_alloc)
+	  FROM ` + "`%s`.`x$memory_by_thread_by_current_bytes`" + `
+	  GROUP BY user
+	`
+	sysMemoryByThreadQuery = `
+	SELECT
+	    thread_id,
+	    user,
+	    current_count_used,
+	    current_allocated,
+	    current_avg_alloc,
+	    current_max_alloc
+	  FROM ` + "`%s`.`x$memory_by_thread_by_current_bytes`" + `
+	`
+)
+
+// Tunable flags.
+var (
+	sysMemoryByThreadPerThread = kingpin.Flag(
+		"collect.sys.memory_by_thread.per_thread",
+		"Collect memory usage per thread instead of aggregating threads per user",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	sysMemoryByUserCountUsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_user_current_count_used"),
+		"The current number of allocated memory blocks that have not been freed yet for the user.",
+		[]string{"user"}, nil,
+	)
+	sysMemoryByUserAllocatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_user_current_allocated_bytes"),
+		"The current number of allocated bytes that have not been freed yet for the user.",
+		[]string{"user"}, nil,
+	)
+	sysMemoryByUserAvgAllocDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_user_current_avg_alloc_bytes"),
+		"The current number of allocated bytes per memory block for the user.",
+		[]string{"user"}, nil,
+	)
+	sysMemoryByUserMaxAllocDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_user_current_max_alloc_bytes"),
+		"The largest single current memory allocation in bytes of any thread of the user.",
+		[]string{"user"}, nil,
+	)
+	sysMemoryByThreadCountUsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_thread_current_count_used"),
+		"The current number of allocated memory blocks that have not been freed yet for the thread.",
+		[]string{"thread_id", "user"}, nil,
+	)
+	sysMemoryByThreadAllocatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_thread_current_allocated_bytes"),
+		"The current number of allocated bytes that have not been freed yet for the thread.",
+		[]string{"thread_id", "user"}, nil,
+	)
+	sysMemoryByThreadAvgAllocDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_thread_current_avg_alloc_bytes"),
+		"The current number of allocated bytes per memory block for the thread.",
+		[]string{"thread_id", "user"}, nil,
+	)
+	sysMemoryByThreadMaxAllocDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "memory_by_thread_current_max_alloc_bytes"),
+		"The largest single current memory allocation in bytes for the thread.",
+		[]string{"thread_id", "user"}, nil,
+	)
+)
+
+// ScrapeSysMemoryByThread collects from `sys.x$memory_by_thread_by_current_bytes`.
+type ScrapeSysMemoryByThread struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSysMemoryByThread) Name() string {
+	return sysSchema + ".memory_by_thread"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSysMemoryByThread) Help() string {
+	return "Collect current memory usage from sys.x$memory_by_thread_by_current_bytes, aggregated per user"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSysMemoryByThread) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSysMemoryByThread) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	if *sysMemoryByThreadPerThread {
+		return scrapeSysMemoryPerThread(ctx, db, ch)
+	}
+
+	query := withMaxExecutionTime(fmt.Sprintf(sysMemoryByUserQuery, *sysSchemaName))
+	memoryRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSysMemoryByThread{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer memoryRows.Close()
+
+	// Memory freed by another thread than the one allocating it is
+	// subtracted from the freeing thread, so the counters can be negative.
+	var (
+		user                 sql.NullString
+		countUsed, allocated int64
+		maxAlloc             int64
+	)
+
+	for memoryRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := memoryRows.Scan(&user, &countUsed, &allocated, &maxAlloc); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		userLabel := "background"
+		if user.Valid {
+			userLabel = user.String
+		}
+		var avgAlloc float64
+		if countUsed > 0 {
+			avgAlloc = float64(allocated) / float64(countUsed)
+		}
+
+		ch <- prometheus.MustNewConstMetric(sysMemoryByUserCountUsedDesc, prometheus.GaugeValue, float64(countUsed), userLabel)
+		ch <- prometheus.MustNewConstMetric(sysMemoryByUserAllocatedDesc, prometheus.GaugeValue, float64(allocated), userLabel)
+		ch <- prometheus.MustNewConstMetric(sysMemoryByUserAvgAllocDesc, prometheus.GaugeValue, avgAlloc, userLabel)
+		ch <- prometheus.MustNewConstMetric(sysMemoryByUserMaxAllocDesc, prometheus.GaugeValue, float64(maxAlloc), userLabel)
+	}
+	return memoryRows.Err()
+}
+
+func scrapeSysMemoryPerThread(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	query := withMaxExecutionTime(fmt.Sprintf(sysMemoryByThreadQuery, *sysSchemaName))
+	memoryRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSysMemoryByThread{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer memoryRows.Close()
+
+	var (
+		threadID             string
+		user                 sql.NullString
+		countUsed, allocated int64
+		avgAlloc             float64
+		maxAlloc             int64
+	)
+
+	for memoryRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := memoryRows.Scan(&threadID, &user, &countUsed, &allocated, &avgAlloc, &maxAlloc); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		userLabel := "background"
+		if user.Valid {
+			userLabel = user.String
+		}
+
+		ch <- prometheus.MustNewConstMetric(sysMemoryByThreadCountUsedDesc, prometheus.GaugeValue, float64(countUsed), threadID, userLabel)
+		ch <- prometheus.MustNewConstMetric(sysMemoryByThreadAllocatedDesc, prometheus.GaugeValue, float64(allocated), threadID, userLabel)
+		ch <- prometheus.MustNewConstMetric(sysMemoryByThreadAvgAllocDesc, prometheus.GaugeValue, avgAlloc, threadID, userLabel)
+		ch <- prometheus.MustNewConstMetric(sysMemoryByThreadMaxAllocDesc, prometheus.GaugeValue, float64(maxAlloc), threadID, userLabel)
+	}
+	return memoryRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSysMemoryByThread{}
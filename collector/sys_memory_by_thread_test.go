@@ -0,0 +1,125 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeSysMemoryByThread(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "SUM(current_count_used)", "SUM(current_allocated)", "MAX(current_max_alloc)"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app@10.0.0.1", "40", "4096", "1024").
+		AddRow("innodb/srv_master_thread", "-3", "-128", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysMemoryByUserQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysMemoryByThread{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"user": "app@10.0.0.1"}, value: 40, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app@10.0.0.1"}, value: 4096, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app@10.0.0.1"}, value: 102.4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app@10.0.0.1"}, value: 1024, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "innodb/srv_master_thread"}, value: -3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "innodb/srv_master_thread"}, value: -128, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "innodb/srv_master_thread"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "innodb/srv_master_thread"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysMemoryByThreadPerThread(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.sys.memory_by_thread.per_thread"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"thread_id", "user", "current_count_used", "current_allocated", "current_avg_alloc", "current_max_alloc"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("42", "app@10.0.0.1", "10", "2048", "204.8000", "1024").
+		AddRow("1", nil, "-2", "-64", "32.0000", "48")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysMemoryByThreadQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysMemoryByThread{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"thread_id": "42", "user": "app@10.0.0.1"}, value: 10, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "42", "user": "app@10.0.0.1"}, value: 2048, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "42", "user": "app@10.0.0.1"}, value: 204.8, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "42", "user": "app@10.0.0.1"}, value: 1024, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "1", "user": "background"}, value: -2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "1", "user": "background"}, value: -64, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "1", "user": "background"}, value: 32, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"thread_id": "1", "user": "background"}, value: 48, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$schema_table_lock_waits`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sysSchemaTableLockWaitsQuery returns a row per waiting and blocking session
+// pair. The waiting query is not selected, it is too large to be a label.
+const sysSchemaTableLockWaitsQuery = `
+	SELECT waiting_pid, blocking_pid, waiting_query_secs
+	  FROM ` + "`%s`.`x$schema_table_lock_waits`" + `
+	`
+
+// Metric descriptors.
+var (
+	sysTableLockWaitsBlockedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "table_lock_waits_blocked_sessions"),
+		"The number of sessions currently waiting for a metadata lock held by another session.",
+		nil, nil,
+	)
+	sysTableLockWaitsBlockingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "table_lock_waits_blocking_sessions"),
+		"The number of sessions currently holding a metadata lock other sessions wait for.",
+		nil, nil,
+	)
+	sysTableLockWaitsMaxAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "table_lock_waits_max_wait_seconds"),
+		"The time the longest waiting query has been waiting for a metadata lock, 0 without waits.",
+		nil, nil,
+	)
+)
+
+// ScrapeSchemaTableLockWaits collects from `sys.x$schema_table_lock_waits`.
+type ScrapeSchemaTableLockWaits struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSchemaTableLockWaits) Name() string {
+	return sysSchema + ".schema_table_lock_waits"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSchemaTableLockWaits) Help() string {
+	return "Collect the sessions currently blocked on metadata locks from sys.x$schema_table_lock_waits"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSchemaTableLockWaits) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSchemaTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysSchemaTableLockWaitsQuery, *sysSchemaName))
+	lockWaitsRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSchemaTableLockWaits{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer lockWaitsRows.Close()
+
+	var (
+		waitingPID, blockingPID sql.NullInt64
+		waitSeconds             sql.NullFloat64
+		maxWait                 float64
+	)
+	// A session waiting on a lock shared by several sessions appears once per
+	// blocking session.
+	waiting := map[int64]bool{}
+	blocking := map[int64]bool{}
+	for lockWaitsRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := lockWaitsRows.Scan(&waitingPID, &blockingPID, &waitSeconds); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		if waitingPID.Valid {
+			waiting[waitingPID.Int64] = true
+		}
+		if blockingPID.Valid {
+			blocking[blockingPID.Int64] = true
+		}
+		if waitSeconds.Valid && waitSeconds.Float64 > maxWait {
+			maxWait = waitSeconds.Float64
+		}
+	}
+	if err := lockWaitsRows.Err(); err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(sysTableLockWaitsBlockedDesc, prometheus.GaugeValue, float64(len(waiting)))
+	ch <- prometheus.MustNewConstMetric(sysTableLockWaitsBlockingDesc, prometheus.GaugeValue, float64(len(blocking)))
+	ch <- prometheus.MustNewConstMetric(sysTableLockWaitsMaxAgeDesc, prometheus.GaugeValue, maxWait)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeSchemaTableLockWaits{}
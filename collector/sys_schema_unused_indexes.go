@@ -0,0 +1,107 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.schema_unused_indexes`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const sysSchemaUnusedIndexesQuery = `
+	SELECT object_schema, object_name, index_name
+	  FROM ` + "`%s`.`schema_unused_indexes`" + `
+	  WHERE object_schema REGEXP ?
+	    AND object_schema NOT REGEXP ?
+	`
+
+// Tunable flags.
+var (
+	sysSchemaUnusedIndexesInclude = kingpin.Flag(
+		"collect.sys.schema_unused_indexes.schema_include",
+		"RegEx of schemas to collect unused indexes for",
+	).Default(".*").String()
+	sysSchemaUnusedIndexesExclude = kingpin.Flag(
+		"collect.sys.schema_unused_indexes.schema_exclude",
+		"RegEx of schemas to skip when collecting unused indexes",
+	).Default("^$").String()
+)
+
+// Metric descriptors.
+var (
+	sysSchemaUnusedIndexDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "schema_unused_index"),
+		"An index that has not been used since the server started, always 1.",
+		[]string{"schema", "table", "index"}, nil,
+	)
+)
+
+// ScrapeUnusedIndexes collects from `sys.schema_unused_indexes`.
+type ScrapeUnusedIndexes struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeUnusedIndexes) Name() string {
+	return sysSchema + ".schema_unused_indexes"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeUnusedIndexes) Help() string {
+	return "Collect the indexes not used since the server started from sys.schema_unused_indexes"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeUnusedIndexes) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeUnusedIndexes) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysSchemaUnusedIndexesQuery, *sysSchemaName))
+	unusedIndexesRows, err := queryContext(ctx, db, query,
+		*sysSchemaUnusedIndexesInclude, *sysSchemaUnusedIndexesExclude)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeUnusedIndexes{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer unusedIndexesRows.Close()
+
+	var schema, table, index string
+	for unusedIndexesRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := unusedIndexesRows.Scan(&schema, &table, &index); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(sysSchemaUnusedIndexDesc, prometheus.GaugeValue, 1, schema, table, index)
+	}
+	return unusedIndexesRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeUnusedIndexes{}
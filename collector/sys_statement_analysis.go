@@ -0,0 +1,176 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$statement_analysis`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// full_scan is '*' for statements that did not use a good index.
+const sysStatementAnalysisQuery = `
+	SELECT
+	    ifnull(db, '') as db,
+	    digest,
+	    exec_count,
+	    total_latency,
+	    avg_latency,
+	    rows_sent_avg,
+	    rows_examined_avg,
+	    tmp_tables,
+	    tmp_disk_tables,
+	    rows_sorted,
+	    full_scan = '*' as full_scan
+	  FROM ` + "`%s`.`x$statement_analysis`" + `
+	  ORDER BY total_latency DESC
+	  LIMIT %d
+	`
+
+// Tunable flags.
+var (
+	sysStatementAnalysisLimit = kingpin.Flag(
+		"collect.sys.statement_analysis.limit",
+		"Limit the number of statement digests, ordered by total latency",
+	).Default("50").Int()
+	sysStatementAnalysisDigestLength = kingpin.Flag(
+		"collect.sys.statement_analysis.digest_length",
+		"Number of leading characters of the statement digest used as label, 0 for the full digest",
+	).Default("16").Int()
+)
+
+// Metric descriptors.
+var (
+	sysStatementAnalysisExecDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_exec_total"),
+		"The total number of occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_latency_seconds_total"),
+		"The total wait time of timed occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisAvgLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_avg_latency_seconds"),
+		"The average wait time per timed occurrence of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisRowsSentAvgDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_rows_sent_avg"),
+		"The average number of rows returned per occurrence of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisRowsExaminedAvgDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_rows_examined_avg"),
+		"The average number of rows read from storage engines per occurrence of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisTmpTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_tmp_tables_total"),
+		"The total number of internal in-memory temporary tables created by occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisTmpDiskTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_tmp_disk_tables_total"),
+		"The total number of internal on-disk temporary tables created by occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisRowsSortedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_rows_sorted_total"),
+		"The total number of rows sorted by occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementAnalysisFullScanDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_analysis_full_scan"),
+		"Whether occurrences of the statement performed full table scans (1) or not (0).",
+		[]string{"schema", "digest"}, nil,
+	)
+)
+
+// ScrapeStatementAnalysis collects from `sys.x$statement_analysis`.
+type ScrapeStatementAnalysis struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeStatementAnalysis) Name() string {
+	return sysSchema + ".statement_analysis"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeStatementAnalysis) Help() string {
+	return "Collect latency, rows and temporary tables of the slowest statement digests from sys.x$statement_analysis"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeStatementAnalysis) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeStatementAnalysis) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysStatementAnalysisQuery, *sysSchemaName, *sysStatementAnalysisLimit))
+	analysisRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeStatementAnalysis{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer analysisRows.Close()
+
+	var (
+		schema, digest                       string
+		execCount, totalLatency              uint64
+		avgLatency, rowsSentAvg, rowsExamAvg float64
+		tmpTables, tmpDiskTables, rowsSorted uint64
+		fullScan                             float64
+	)
+	for analysisRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := analysisRows.Scan(
+			&schema, &digest, &execCount, &totalLatency, &avgLatency, &rowsSentAvg, &rowsExamAvg,
+			&tmpTables, &tmpDiskTables, &rowsSorted, &fullScan,
+		); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		digestLabel := shortDigest(digest, *sysStatementAnalysisDigestLength)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisExecDesc, prometheus.CounterValue, float64(execCount), schema, digestLabel)
+		newConstMetricFromSeconds(ch, sysStatementAnalysisLatencyDesc, prometheus.CounterValue, totalLatency, schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisAvgLatencyDesc, prometheus.GaugeValue, avgLatency/picoSeconds, schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisRowsSentAvgDesc, prometheus.GaugeValue, rowsSentAvg, schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisRowsExaminedAvgDesc, prometheus.GaugeValue, rowsExamAvg, schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisTmpTablesDesc, prometheus.CounterValue, float64(tmpTables), schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisTmpDiskTablesDesc, prometheus.CounterValue, float64(tmpDiskTables), schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisRowsSortedDesc, prometheus.CounterValue, float64(rowsSorted), schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementAnalysisFullScanDesc, prometheus.GaugeValue, fullScan, schema, digestLabel)
+	}
+	return analysisRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeStatementAnalysis{}
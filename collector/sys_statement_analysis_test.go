@@ -0,0 +1,86 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeStatementAnalysis(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.statement_analysis.limit", "1",
+		"--collect.sys.statement_analysis.digest_length", "12",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The limit is applied by the query, ordered by total latency.
+	columns := []string{
+		"db", "digest", "exec_count", "total_latency", "avg_latency", "rows_sent_avg", "rows_examined_avg",
+		"tmp_tables", "tmp_disk_tables", "rows_sorted", "full_scan",
+	}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", "2f1a3c9d8e7b6a5f4e3d2c1b0a998877", "200", "50000000000000", "250000000000.0000", "1.5000", "1000.0000", "10", "2", "400", "1")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysStatementAnalysisQuery, "sys", 1))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeStatementAnalysis{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	labels := labelMap{"schema": "shop", "digest": "2f1a3c9d8e7b"}
+	metricExpected := []MetricResult{
+		{labels: labels, value: 200, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 50, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 0.25, metricType: dto.MetricType_GAUGE},
+		{labels: labels, value: 1.5, metricType: dto.MetricType_GAUGE},
+		{labels: labels, value: 1000, metricType: dto.MetricType_GAUGE},
+		{labels: labels, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 400, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$statements_with_errors_or_warnings`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const sysStatementsWithErrorsQuery = `
+	SELECT
+	    ifnull(db, '') as db,
+	    digest,
+	    errors,
+	    warnings
+	  FROM ` + "`%s`.`x$statements_with_errors_or_warnings`" + `
+	  ORDER BY errors DESC
+	  LIMIT %d
+	`
+
+// Tunable flags.
+var (
+	sysStatementsWithErrorsLimit = kingpin.Flag(
+		"collect.sys.statements_with_errors.limit",
+		"Limit the number of statement digests, ordered by errors",
+	).Default("100").Int()
+	sysStatementsWithErrorsDigestLength = kingpin.Flag(
+		"collect.sys.statements_with_errors.digest_length",
+		"Number of leading characters of the statement digest used as label, 0 for the full digest",
+	).Default("16").Int()
+)
+
+// Metric descriptors.
+var (
+	sysStatementsErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_errors_total"),
+		"The total number of errors produced by occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+	sysStatementsWarningsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "statement_warnings_total"),
+		"The total number of warnings produced by occurrences of the statement.",
+		[]string{"schema", "digest"}, nil,
+	)
+)
+
+// ScrapeSysStatementsWithErrors collects from `sys.x$statements_with_errors_or_warnings`.
+type ScrapeSysStatementsWithErrors struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSysStatementsWithErrors) Name() string {
+	return sysSchema + ".statements_with_errors"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSysStatementsWithErrors) Help() string {
+	return "Collect per statement digest errors and warnings from sys.x$statements_with_errors_or_warnings"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSysStatementsWithErrors) Version() float64 {
+	return 5.7
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSysStatementsWithErrors) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysStatementsWithErrorsQuery, *sysSchemaName, *sysStatementsWithErrorsLimit))
+	statementsRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSysStatementsWithErrors{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer statementsRows.Close()
+
+	var (
+		schema, digest   string
+		errors, warnings uint64
+	)
+
+	for statementsRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := statementsRows.Scan(&schema, &digest, &errors, &warnings); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		digestLabel := shortDigest(digest, *sysStatementsWithErrorsDigestLength)
+		ch <- prometheus.MustNewConstMetric(sysStatementsErrorsDesc, prometheus.CounterValue, float64(errors), schema, digestLabel)
+		ch <- prometheus.MustNewConstMetric(sysStatementsWarningsDesc, prometheus.CounterValue, float64(warnings), schema, digestLabel)
+	}
+	return statementsRows.Err()
+}
+
+// shortDigest truncates a statement digest to its first n characters.
+// Digests are hex encoded hashes, so a prefix is still a usable identifier.
+func shortDigest(digest string, n int) string {
+	if n <= 0 || len(digest) <= n {
+		return digest
+	}
+	return digest[:n]
+}
+
+// check interface
+var _ Scraper = ScrapeSysStatementsWithErrors{}
@@ -0,0 +1,83 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeSysStatementsWithErrors(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.statements_with_errors.limit", "2",
+		"--collect.sys.statements_with_errors.digest_length", "8",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"db", "digest", "errors", "warnings"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", "2f1a3c9d8e7b6a5f4e3d2c1b0a998877", "42", "3").
+		AddRow("", "aa11bb22cc33dd44ee55ff6677889900", "5", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysStatementsWithErrorsQuery, "sys", 2))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysStatementsWithErrors{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "digest": "2f1a3c9d"}, value: 42, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "digest": "2f1a3c9d"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "", "digest": "aa11bb22"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "", "digest": "aa11bb22"}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestShortDigest(t *testing.T) {
+	convey.Convey("Digest truncation", t, func() {
+		convey.So(shortDigest("2f1a3c9d8e7b6a5f", 4), convey.ShouldEqual, "2f1a")
+		convey.So(shortDigest("2f1a", 16), convey.ShouldEqual, "2f1a")
+		convey.So(shortDigest("2f1a3c9d8e7b6a5f", 0), convey.ShouldEqual, "2f1a3c9d8e7b6a5f")
+	})
+}
@@ -0,0 +1,414 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$user_summary`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// sysUserSummaryQuery selects all columns, as the set of columns of the view
+// differs between versions. Columns are matched by name in
+// sysUserSummaryColumns.
+const sysUserSummaryQuery = `
+	SELECT *
+	  FROM ` + "`%s`.`x$user_summary`" + `
+	`
+
+// sysUserSummaryMaxLatencyQuery returns the longest statement per user, which
+// x$user_summary does not provide.
+const sysUserSummaryMaxLatencyQuery = `
+	SELECT USER, MAX(MAX_TIMER_WAIT)
+	  FROM performance_schema.events_statements_summary_by_user_by_event_name
+	  GROUP BY USER
+	`
+
+// Tunable flags.
+var (
+	sysUserSummaryMetrics = kingpin.Flag(
+		"collect.sys.user_summary.metrics",
+		"Comma separated list of sys.user_summary columns to export, e.g. statements,statement_latency, or empty for all",
+	).Default("").String()
+	sysUserSummaryNormalizeLabels = kingpin.Flag(
+		"collect.sys.user_summary.normalize_labels",
+		"Trim user labels and strip the @host part of account names, summing the values of accounts of the same user",
+	).Default("false").Bool()
+	sysUserSummaryDerivedLatency = kingpin.Flag(
+		"collect.sys.user_summary.derived_latency",
+		"Collect the average and maximum statement latency per user",
+	).Default("false").Bool()
+	sysUserSummaryUntyped = kingpin.Flag(
+		"collect.sys.user_summary.untyped",
+		"Export the sys.user_summary counters as untyped metrics, as they decrease when the statistics are reset",
+	).Default("false").Bool()
+	sysUserSummaryIntervalFactor = kingpin.Flag(
+		"collect.sys.user_summary.interval_factor",
+		"Only query sys.user_summary every this many scrapes, sending the metrics of the last query in between",
+	).Default("1").Int()
+)
+
+// sysUserLabels interns the user and statement labels of the sys user
+// summaries, which repeat in every scrape.
+var sysUserLabels = newLabelCache()
+
+// Metric descriptors.
+var (
+	sysUserSummaryStatements = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statements_total"),
+		"The total number of statements for the user.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryStatementLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_latency_seconds_total"),
+		"The total wait time of timed statements for the user in seconds.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryStatementAvgLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_avg_latency_seconds"),
+		"The average wait time of timed statements for the user in seconds.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryStatementMaxLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_max_latency_seconds"),
+		"The maximum wait time of a single statement for the user in seconds.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryTableScans = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_table_scans_total"),
+		"The total number of table scans for the user.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryFileIOs = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_file_ios_total"),
+		"The total number of file I/O events for the user.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryFileIOLatency = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_file_io_latency_seconds_total"),
+		"The total wait time of timed file I/O events for the user in seconds.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryCurrentConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_current_connections"),
+		"The current number of connections for the user.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryTotalConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_connections_total"),
+		"The total number of connections for the user.",
+		[]string{"user"}, nil,
+	)
+	sysUserSummaryUniqueHosts = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_unique_hosts"),
+		"The number of distinct hosts from which connections for the user have originated.",
+		[]string{"user"}, nil,
+	)
+)
+
+// sysUserSummaryColumn maps a column of x$user_summary to its metric.
+type sysUserSummaryColumn struct {
+	desc  *prometheus.Desc
+	vtype prometheus.ValueType
+	// picoSeconds marks timer columns, exported as seconds.
+	picoSeconds bool
+}
+
+// sysUserSummaryColumns lists the columns exported as metrics, other columns
+// are ignored.
+var sysUserSummaryColumns = map[string]sysUserSummaryColumn{
+	"statements":          {sysUserSummaryStatements, prometheus.CounterValue, false},
+	"statement_latency":   {sysUserSummaryStatementLatency, prometheus.CounterValue, true},
+	"table_scans":         {sysUserSummaryTableScans, prometheus.CounterValue, false},
+	"file_ios":            {sysUserSummaryFileIOs, prometheus.CounterValue, false},
+	"file_io_latency":     {sysUserSummaryFileIOLatency, prometheus.CounterValue, true},
+	"current_connections": {sysUserSummaryCurrentConnections, prometheus.GaugeValue, false},
+	"total_connections":   {sysUserSummaryTotalConnections, prometheus.CounterValue, false},
+	"unique_hosts":        {sysUserSummaryUniqueHosts, prometheus.GaugeValue, false},
+}
+
+// sysUserSummaryUser holds the summed column values of a user label.
+type sysUserSummaryUser struct {
+	user   string
+	values map[string]float64
+	// timers holds timer columns in picoseconds.
+	timers map[string]uint64
+	// statements and statementLatency are kept for the derived average
+	// latency regardless of the exported columns.
+	statements       uint64
+	statementLatency uint64
+}
+
+// collect sends the summed values of the enabled columns, in the order of
+// the query columns. Counters are sent as untyped if untyped is set.
+func (u *sysUserSummaryUser) collect(ch chan<- prometheus.Metric, columns []string, enabled map[string]sysUserSummaryColumn, untyped bool, resets *resetTracker) {
+	for _, column := range columns {
+		metric := enabled[column]
+		vtype := metric.vtype
+		if vtype == prometheus.CounterValue && untyped {
+			vtype = prometheus.UntypedValue
+		}
+		if value, ok := u.timers[column]; ok {
+			resets.observe(u.user+"/"+column, float64(value))
+			newConstMetricFromSeconds(ch, metric.desc, vtype, value, u.user)
+			continue
+		}
+		value, ok := u.values[column]
+		if !ok {
+			continue
+		}
+		if metric.vtype == prometheus.CounterValue {
+			resets.observe(u.user+"/"+column, value)
+		}
+		ch <- prometheus.MustNewConstMetric(metric.desc, vtype, value, u.user)
+	}
+}
+
+// collectDerivedLatency sends the average statement latency and the maximum
+// statement latency found in maxLatencies.
+func (u *sysUserSummaryUser) collectDerivedLatency(ch chan<- prometheus.Metric, maxLatencies map[string]uint64) {
+	var avg float64
+	if u.statements > 0 {
+		avg = float64(u.statementLatency) / picoSeconds / float64(u.statements)
+	}
+	ch <- prometheus.MustNewConstMetric(sysUserSummaryStatementAvgLatency, prometheus.GaugeValue, avg, u.user)
+	if maxLatency, ok := maxLatencies[u.user]; ok {
+		newConstMetricFromSeconds(ch, sysUserSummaryStatementMaxLatency, prometheus.GaugeValue, maxLatency, u.user)
+	}
+}
+
+// ScrapeSysUserSummary collects from `sys.x$user_summary`.
+type ScrapeSysUserSummary struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSysUserSummary) Name() string {
+	return sysSchema + ".user_summary"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSysUserSummary) Help() string {
+	return "Collect per user metrics from sys.x$user_summary. See https://dev.mysql.com/doc/refman/5.7/en/sys-user-summary.html for details"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSysUserSummary) Version() float64 {
+	return 5.7
+}
+
+// IntervalFactor is the number of scrapes the scraper runs once in.
+func (ScrapeSysUserSummary) IntervalFactor() int {
+	return *sysUserSummaryIntervalFactor
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSysUserSummary) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	// The flags are read once, as the metrics are sent while reading rows.
+	var (
+		derivedLatency  = *sysUserSummaryDerivedLatency
+		normalizeLabels = *sysUserSummaryNormalizeLabels
+		untyped         = *sysUserSummaryUntyped
+	)
+	var maxLatencies map[string]uint64
+	if derivedLatency {
+		var err error
+		if maxLatencies, err = sysUserMaxLatencies(ctx, db, normalizeLabels); err != nil {
+			return err
+		}
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysUserSummaryQuery, *sysSchemaName))
+	userSummaryRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSysUserSummary{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer userSummaryRows.Close()
+
+	columns, err := userSummaryRows.Columns()
+	if err != nil {
+		return err
+	}
+	enabled, err := sysUserSummaryEnabledColumns(*sysUserSummaryMetrics)
+	if err != nil {
+		return err
+	}
+	resets := newResetTracker(ctx, ScrapeSysUserSummary{}.Name())
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+
+	send := func(u *sysUserSummaryUser) {
+		u.collect(ch, columns, enabled, untyped, resets)
+		if derivedLatency {
+			u.collectDerivedLatency(ch, maxLatencies)
+		}
+	}
+
+	// With normalized labels, rows are summed per user and sent once all rows
+	// are read, as accounts of different hosts collapse into the same user.
+	// Otherwise each row is sent as soon as it is read.
+	var users []*sysUserSummaryUser
+	byUser := map[string]*sysUserSummaryUser{}
+	for userSummaryRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := userSummaryRows.Scan(scanArgs...); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		// Background threads are reported without a user.
+		user := "background"
+		for i, column := range columns {
+			if value := *scanArgs[i].(*sql.RawBytes); column == "user" && value != nil {
+				user = sysUserLabels.intern(value)
+			}
+		}
+		if normalizeLabels {
+			user = normalizeSysUser(user)
+		}
+		sums, ok := byUser[user]
+		if !ok {
+			sums = &sysUserSummaryUser{user: user, values: map[string]float64{}, timers: map[string]uint64{}}
+			if normalizeLabels {
+				byUser[user] = sums
+				users = append(users, sums)
+			}
+		}
+
+		for i, column := range columns {
+			raw := string(*scanArgs[i].(*sql.RawBytes))
+			switch column {
+			case "statements":
+				value, _ := strconv.ParseUint(raw, 10, 64)
+				sums.statements += value
+			case "statement_latency":
+				value, _ := strconv.ParseUint(raw, 10, 64)
+				sums.statementLatency += value
+			}
+			metric, ok := enabled[column]
+			if !ok {
+				continue
+			}
+			if metric.picoSeconds {
+				value, err := strconv.ParseUint(raw, 10, 64)
+				if err != nil {
+					// Skip NULL and unparsable values.
+					continue
+				}
+				sums.timers[column] += value
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				// Skip NULL and unparsable values.
+				continue
+			}
+			sums.values[column] += value
+		}
+		if !normalizeLabels {
+			send(sums)
+		}
+	}
+	if err := userSummaryRows.Err(); err != nil {
+		return err
+	}
+	for _, sums := range users {
+		send(sums)
+	}
+	resets.collect(ch)
+	return nil
+}
+
+// sysUserMaxLatencies returns the maximum statement latency in picoseconds
+// by user label, normalized if normalizeLabels is set.
+func sysUserMaxLatencies(ctx context.Context, db *sql.DB, normalizeLabels bool) (map[string]uint64, error) {
+	maxLatencyRows, err := queryContext(ctx, db, sysUserSummaryMaxLatencyQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer maxLatencyRows.Close()
+
+	maxLatencies := map[string]uint64{}
+	var (
+		user       sql.NullString
+		maxLatency uint64
+	)
+	for maxLatencyRows.Next() {
+		if err := maxLatencyRows.Scan(&user, &maxLatency); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return nil, err
+		}
+		userLabel := "background"
+		if user.Valid {
+			userLabel = user.String
+		}
+		if normalizeLabels {
+			userLabel = normalizeSysUser(userLabel)
+		}
+		if last, ok := maxLatencies[userLabel]; !ok || maxLatency > last {
+			maxLatencies[userLabel] = maxLatency
+		}
+	}
+	return maxLatencies, maxLatencyRows.Err()
+}
+
+// sysUserSummaryEnabledColumns returns the columns selected by a comma
+// separated list, all columns when the list is empty.
+func sysUserSummaryEnabledColumns(list string) (map[string]sysUserSummaryColumn, error) {
+	if strings.TrimSpace(list) == "" {
+		return sysUserSummaryColumns, nil
+	}
+	enabled := map[string]sysUserSummaryColumn{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		metric, ok := sysUserSummaryColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sys.user_summary metric %q", name)
+		}
+		enabled[name] = metric
+	}
+	return enabled, nil
+}
+
+// normalizeSysUser trims an account name and strips its host part and
+// quotes, so that 'app'@'10.0.0.1' and app@10.0.0.2 both become app.
+func normalizeSysUser(account string) string {
+	user := strings.TrimSpace(account)
+	if i := strings.LastIndex(user, "@"); i >= 0 {
+		user = user[:i]
+	}
+	return strings.Trim(strings.TrimSpace(user), "'`\"")
+}
+
+// check interface
+var _ Scraper = ScrapeSysUserSummary{}
+var _ Sampled = ScrapeSysUserSummary{}
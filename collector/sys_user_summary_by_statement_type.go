@@ -0,0 +1,328 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `sys.x$user_summary_by_statement_type`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const sysUserSummaryByStatementTypeQuery = `
+	SELECT
+	    user,
+	    statement,
+	    total,
+	    total_latency,
+	    max_latency,
+	    lock_latency,
+	    rows_sent,
+	    rows_examined,
+	    rows_affected,
+	    full_scans
+	  FROM ` + "`%s`.`x$user_summary_by_statement_type`" + `
+	`
+
+// sysOtherStatement is the statement label of rarely seen statement types.
+const sysOtherStatement = "other"
+
+// Tunable flags.
+var (
+	sysUserSummaryNullPlaceholder = kingpin.Flag(
+		"collect.sys.user_summary.null_placeholder",
+		"Label of the NULL users and statements of sys.user_summary_by_statement_type, e.g. of background threads",
+	).Default("background").String()
+	sysUserSummaryEfficiencyRatio = kingpin.Flag(
+		"collect.sys.user_summary.efficiency_ratio",
+		"Collect the number of rows examined per row sent by user and statement type from sys.user_summary_by_statement_type",
+	).Default("false").Bool()
+	sysUserSummaryUserInclude = kingpin.Flag(
+		"collect.sys.user_summary.user_include",
+		"RegEx of users to collect sys.user_summary_by_statement_type metrics for",
+	).Default(".*").String()
+	sysUserSummaryUserExclude = kingpin.Flag(
+		"collect.sys.user_summary.user_exclude",
+		"RegEx of users to skip when collecting sys.user_summary_by_statement_type metrics, empty to skip none",
+	).Default("").String()
+	sysUserSummaryOtherThreshold = kingpin.Flag(
+		"collect.sys.user_summary.other_threshold",
+		"Report the statement types of sys.user_summary_by_statement_type executed fewer times than this across all users as \"other\", 0 to disable",
+	).Default("0").Uint64()
+	sysUserSummaryMaxSeries = kingpin.Flag(
+		"collect.sys.user_summary.max_series",
+		"Maximum number of user and statement type pairs to collect, keeping the most executed ones, 0 for no limit",
+	).Default("0").Int()
+)
+
+// Metric descriptors.
+var (
+	sysUserStatementTypeTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_total"),
+		"The total number of occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_latency_seconds_total"),
+		"The total wait time of timed occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeMaxLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_max_latency_seconds"),
+		"The maximum wait time of a single occurrence of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeLockLatencyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_lock_latency_seconds_total"),
+		"The total time waited for locks by occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeRowsSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_rows_sent_total"),
+		"The total number of rows returned by occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeRowsExaminedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_rows_examined_total"),
+		"The total number of rows read from storage engines by occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeRowsAffectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_rows_affected_total"),
+		"The total number of rows affected by occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserStatementTypeFullScansDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_statement_type_full_scans_total"),
+		"The total number of full table scans by occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserRowsExaminedPerSentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_rows_examined_per_sent"),
+		"The number of rows examined per row sent by occurrences of the statement type for the user.",
+		[]string{"user", "statement"}, nil,
+	)
+	sysUserSummaryDroppedSeriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sysSchema, "user_summary_dropped_series"),
+		"The number of user and statement type pairs not collected because of --collect.sys.user_summary.max_series.",
+		nil, nil,
+	)
+)
+
+// sysUserStatementType holds the values of a statement type for a user.
+type sysUserStatementType struct {
+	user, statement                      string
+	total, latency, maxLatency, lockTime uint64
+	rowsSent, rowsExamined, rowsAffected uint64
+	fullScans                            uint64
+}
+
+// add sums the values of o, keeping the highest maximum latency.
+func (s *sysUserStatementType) add(o sysUserStatementType) {
+	s.total += o.total
+	s.latency += o.latency
+	if o.maxLatency > s.maxLatency {
+		s.maxLatency = o.maxLatency
+	}
+	s.lockTime += o.lockTime
+	s.rowsSent += o.rowsSent
+	s.rowsExamined += o.rowsExamined
+	s.rowsAffected += o.rowsAffected
+	s.fullScans += o.fullScans
+}
+
+// ScrapeSysUserSummaryByStatementType collects from `sys.x$user_summary_by_statement_type`.
+type ScrapeSysUserSummaryByStatementType struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSysUserSummaryByStatementType) Name() string {
+	return sysSchema + ".user_summary_by_statement_type"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSysUserSummaryByStatementType) Help() string {
+	return "Collect per user and statement type metrics from sys.x$user_summary_by_statement_type"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSysUserSummaryByStatementType) Version() float64 {
+	return 5.7
+}
+
+// Requires the sys schema to be installed.
+func (ScrapeSysUserSummaryByStatementType) Requires() []string {
+	return []string{FeatureSysSchema}
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSysUserSummaryByStatementType) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if !sysSchemaSupported(ctx) {
+		return nil
+	}
+	include, err := regexp.Compile(*sysUserSummaryUserInclude)
+	if err != nil {
+		return err
+	}
+	var exclude *regexp.Regexp
+	if *sysUserSummaryUserExclude != "" {
+		if exclude, err = regexp.Compile(*sysUserSummaryUserExclude); err != nil {
+			return err
+		}
+	}
+	query := withMaxExecutionTime(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, *sysSchemaName))
+	statementTypeRows, err := queryContext(ctx, db, query)
+	if err != nil {
+		if isTableMissing(err) {
+			warnSysSchemaMissing(ScrapeSysUserSummaryByStatementType{}.Name(), err)
+			return nil
+		}
+		return err
+	}
+	defer statementTypeRows.Close()
+
+	// Columns are matched by name, as the view definition differs between
+	// sys schema versions.
+	columns, err := statementTypeRows.Columns()
+	if err != nil {
+		return err
+	}
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+
+	// All rows are read first, as the "other" bucket depends on the
+	// occurrences of each statement type across all users.
+	placeholder := *sysUserSummaryNullPlaceholder
+	efficiencyRatio := *sysUserSummaryEfficiencyRatio
+	otherThreshold := *sysUserSummaryOtherThreshold
+	maxSeries := *sysUserSummaryMaxSeries
+	var rows []sysUserStatementType
+	statementTotals := map[string]uint64{}
+	for statementTypeRows.Next() {
+		if err := contextDone(ctx); err != nil {
+			return err
+		}
+		if err := statementTypeRows.Scan(scanArgs...); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		// Background threads have a NULL user.
+		r := sysUserStatementType{user: placeholder, statement: placeholder}
+		for i, column := range columns {
+			raw := *scanArgs[i].(*sql.RawBytes)
+			if raw == nil {
+				continue
+			}
+			var counter *uint64
+			switch column {
+			case "user":
+				r.user = sysUserLabels.intern(raw)
+			case "statement":
+				r.statement = normalizeStatementType(sysUserLabels.intern(raw))
+			case "total":
+				counter = &r.total
+			case "total_latency":
+				counter = &r.latency
+			case "max_latency":
+				counter = &r.maxLatency
+			case "lock_latency":
+				counter = &r.lockTime
+			case "rows_sent":
+				counter = &r.rowsSent
+			case "rows_examined":
+				counter = &r.rowsExamined
+			case "rows_affected":
+				counter = &r.rowsAffected
+			case "full_scans":
+				counter = &r.fullScans
+			}
+			if counter != nil {
+				// Unparsable values are reported as 0.
+				*counter, _ = strconv.ParseUint(string(raw), 10, 64)
+			}
+		}
+		if !include.MatchString(r.user) || (exclude != nil && exclude.MatchString(r.user)) {
+			continue
+		}
+		statementTotals[r.statement] += r.total
+		rows = append(rows, r)
+	}
+	if err := statementTypeRows.Err(); err != nil {
+		return err
+	}
+
+	var summaries []*sysUserStatementType
+	byLabels := map[[2]string]*sysUserStatementType{}
+	for _, r := range rows {
+		if statementTotals[r.statement] < otherThreshold {
+			r.statement = sysOtherStatement
+		}
+		key := [2]string{r.user, r.statement}
+		summary, ok := byLabels[key]
+		if !ok {
+			summary = &sysUserStatementType{user: r.user, statement: r.statement}
+			byLabels[key] = summary
+			summaries = append(summaries, summary)
+		}
+		summary.add(r)
+	}
+
+	// Keep the most executed pairs when there are more than the limit.
+	var dropped int
+	if maxSeries > 0 && len(summaries) > maxSeries {
+		sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].total > summaries[j].total })
+		dropped = len(summaries) - maxSeries
+		summaries = summaries[:maxSeries]
+	}
+
+	for _, s := range summaries {
+		ch <- prometheus.MustNewConstMetric(sysUserStatementTypeTotalDesc, prometheus.CounterValue, uint64ToFloat(ScrapeSysUserSummaryByStatementType{}.Name(), "total", s.total), s.user, s.statement)
+		newConstMetricFromSeconds(ch, sysUserStatementTypeLatencyDesc, prometheus.CounterValue, s.latency, s.user, s.statement)
+		newConstMetricFromSeconds(ch, sysUserStatementTypeMaxLatencyDesc, prometheus.GaugeValue, s.maxLatency, s.user, s.statement)
+		newConstMetricFromSeconds(ch, sysUserStatementTypeLockLatencyDesc, prometheus.CounterValue, s.lockTime, s.user, s.statement)
+		ch <- prometheus.MustNewConstMetric(sysUserStatementTypeRowsSentDesc, prometheus.CounterValue, uint64ToFloat(ScrapeSysUserSummaryByStatementType{}.Name(), "rows_sent", s.rowsSent), s.user, s.statement)
+		ch <- prometheus.MustNewConstMetric(sysUserStatementTypeRowsExaminedDesc, prometheus.CounterValue, uint64ToFloat(ScrapeSysUserSummaryByStatementType{}.Name(), "rows_examined", s.rowsExamined), s.user, s.statement)
+		ch <- prometheus.MustNewConstMetric(sysUserStatementTypeRowsAffectedDesc, prometheus.CounterValue, uint64ToFloat(ScrapeSysUserSummaryByStatementType{}.Name(), "rows_affected", s.rowsAffected), s.user, s.statement)
+		ch <- prometheus.MustNewConstMetric(sysUserStatementTypeFullScansDesc, prometheus.CounterValue, uint64ToFloat(ScrapeSysUserSummaryByStatementType{}.Name(), "full_scans", s.fullScans), s.user, s.statement)
+		// Statements sending no rows, e.g. writes, have no ratio.
+		if efficiencyRatio && s.rowsSent > 0 {
+			ch <- prometheus.MustNewConstMetric(sysUserRowsExaminedPerSentDesc, prometheus.GaugeValue, float64(s.rowsExamined)/float64(s.rowsSent), s.user, s.statement)
+		}
+	}
+	if maxSeries > 0 {
+		ch <- prometheus.MustNewConstMetric(sysUserSummaryDroppedSeriesDesc, prometheus.GaugeValue, float64(dropped))
+	}
+	return nil
+}
+
+// normalizeStatementType lowercases and trims a statement type, as its
+// spelling differs between server versions.
+func normalizeStatementType(statement string) string {
+	return strings.ToLower(strings.TrimSpace(statement))
+}
+
+// check interface
+var _ Scraper = ScrapeSysUserSummaryByStatementType{}
+var _ Requirer = ScrapeSysUserSummaryByStatementType{}
@@ -0,0 +1,391 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var sysUserSummaryByStatementTypeColumns = []string{
+	"user", "statement", "total", "total_latency", "max_latency", "lock_latency",
+	"rows_sent", "rows_examined", "rows_affected", "full_scans",
+}
+
+func TestScrapeSysUserSummaryByStatementType(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(sysUserSummaryByStatementTypeColumns).
+		AddRow("app", " SELECT ", "10", "2000000000000", "500000000000", "1000000000", "100", "1000", "0", "2")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	labels := labelMap{"user": "app", "statement": "select"}
+	metricExpected := []MetricResult{
+		{labels: labels, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 0.5, metricType: dto.MetricType_GAUGE},
+		{labels: labels, value: 0.001, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 2, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryByStatementTypeColumnDrift(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// Columns are reordered, lock_latency is missing and unknown columns are added.
+	columns := []string{"statement", "total", "user", "total_latency", "max_latency", "rows_sent", "rows_examined", "rows_affected", "full_scans", "total_connections"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("select", "10", "app", "2000000000000", "500000000000", "100", "1000", "0", "2", "70")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	labels := labelMap{"user": "app", "statement": "select"}
+	metricExpected := []MetricResult{
+		{labels: labels, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 0.5, metricType: dto.MetricType_GAUGE},
+		{labels: labels, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labels, value: 2, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Columns are matched by name", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryByStatementTypeOther(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.other_threshold", "5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// select is frequent overall, even though rare for report. show and
+	// set_option are rare and are summed into other.
+	rows := sqlmock.NewRows(sysUserSummaryByStatementTypeColumns).
+		AddRow("app", "select", "100", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("report", "select", "2", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("report", "show", "3", "0", "3000000000000", "0", "0", "0", "0", "0").
+		AddRow("report", "Set_Option", "1", "0", "4000000000000", "0", "0", "0", "0", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	totals := map[[2]string]float64{}
+	maxLatencies := map[[2]string]float64{}
+	for m := range ch {
+		got := readMetric(m)
+		key := [2]string{got.labels["user"], got.labels["statement"]}
+		switch m.Desc() {
+		case sysUserStatementTypeTotalDesc:
+			totals[key] = got.value
+		case sysUserStatementTypeMaxLatencyDesc:
+			maxLatencies[key] = got.value
+		}
+	}
+	convey.Convey("Rare statement types are reported as other", t, func() {
+		convey.So(totals, convey.ShouldResemble, map[[2]string]float64{
+			{"app", "select"}:    100,
+			{"report", "select"}: 2,
+			{"report", "other"}:  4,
+		})
+		convey.So(maxLatencies[[2]string{"report", "other"}], convey.ShouldEqual, 4)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryByStatementTypeMaxSeries(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.max_series", "2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(sysUserSummaryByStatementTypeColumns).
+		AddRow("app", "select", "100", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("app", "insert", "5", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("report", "select", "40", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("report", "show", "1", "0", "0", "0", "0", "0", "0", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	totals := map[[2]string]float64{}
+	var dropped []float64
+	for m := range ch {
+		got := readMetric(m)
+		switch m.Desc() {
+		case sysUserStatementTypeTotalDesc:
+			totals[[2]string{got.labels["user"], got.labels["statement"]}] = got.value
+		case sysUserSummaryDroppedSeriesDesc:
+			dropped = append(dropped, got.value)
+		}
+	}
+	convey.Convey("The most executed pairs are kept", t, func() {
+		convey.So(totals, convey.ShouldResemble, map[[2]string]float64{
+			{"app", "select"}:    100,
+			{"report", "select"}: 40,
+		})
+		convey.So(dropped, convey.ShouldResemble, []float64{2})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryByStatementTypeNull(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.null_placeholder", "system",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(sysUserSummaryByStatementTypeColumns).
+		AddRow(nil, "select", "10", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("app", nil, "3", "0", "0", "0", "0", "0", "0", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	totals := map[string]float64{}
+	for m := range ch {
+		got := readMetric(m)
+		if m.Desc() == sysUserStatementTypeTotalDesc {
+			totals[got.labels["user"]+"/"+got.labels["statement"]] = got.value
+		}
+	}
+	convey.Convey("NULL users and statements get the placeholder label", t, func() {
+		convey.So(totals, convey.ShouldResemble, map[string]float64{
+			"system/select": 10,
+			"app/system":    3,
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryByStatementTypeUserFilter(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.user_include", "^app",
+		"--collect.sys.user_summary.user_exclude", "^app_batch$",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(sysUserSummaryByStatementTypeColumns).
+		AddRow("app", "select", "10", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("app_web", "select", "4", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("app_batch", "insert", "7", "0", "0", "0", "0", "0", "0", "0").
+		AddRow("report", "select", "2", "0", "0", "0", "0", "0", "0", "0").
+		AddRow(nil, "select", "1", "0", "0", "0", "0", "0", "0", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	totals := map[string]float64{}
+	for m := range ch {
+		got := readMetric(m)
+		if m.Desc() == sysUserStatementTypeTotalDesc {
+			totals[got.labels["user"]] = got.value
+		}
+	}
+	convey.Convey("Only included and not excluded users are collected", t, func() {
+		convey.So(totals, convey.ShouldResemble, map[string]float64{"app": 10, "app_web": 4})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryByStatementTypeEfficiencyRatio(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.efficiency_ratio",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(sysUserSummaryByStatementTypeColumns).
+		AddRow("app", "select", "10", "0", "0", "0", "20", "1000", "0", "0").
+		// No rows sent, the ratio is skipped.
+		AddRow("app", "update", "5", "0", "0", "0", "0", "50", "5", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryByStatementTypeQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummaryByStatementType{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var ratios []MetricResult
+	for m := range ch {
+		if m.Desc() == sysUserRowsExaminedPerSentDesc {
+			ratios = append(ratios, readMetric(m))
+		}
+	}
+	convey.Convey("Rows examined per row sent", t, func() {
+		convey.So(ratios, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"user": "app", "statement": "select"}, value: 50, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,593 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func TestScrapeSysUserSummary(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	rows := sqlmock.NewRows(columns).
+		// Note, latencies are in picoseconds.
+		AddRow("app", "10", "2000000000000", "3", "40", "5000000000000", "6", "70", "2").
+		AddRow(nil, "11", "3000000000000", "0", "41", "6000000000000", "20", "20", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"user": "app"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 40, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 6, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app"}, value: 70, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "background"}, value: 11, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "background"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "background"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "background"}, value: 41, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "background"}, value: 6, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "background"}, value: 20, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "background"}, value: 20, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "background"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryCustomSchema(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.schema", "monitoring",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	mock.ExpectQuery(sanitizeQuery("FROM `monitoring`.`x$user_summary`")).WillReturnRows(sqlmock.NewRows(columns))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	for range ch {
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryContextCancel(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app1", "1", "1", "1", "1", "1", "1", "1", "1").
+		AddRow("app2", "1", "1", "1", "1", "1", "1", "1", "1").
+		AddRow("app3", "1", "1", "1", "1", "1", "1", "1", "1")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan prometheus.Metric)
+	var scrapeErr error
+	go func() {
+		scrapeErr = (ScrapeSysUserSummary{}).Scrape(ctx, db, ch)
+		close(ch)
+	}()
+
+	// Cancel once the metrics of the first row have been received.
+	for i := 0; i < 8; i++ {
+		<-ch
+	}
+	cancel()
+
+	remaining := 0
+	for range ch {
+		remaining++
+	}
+
+	convey.Convey("Scrape stops on cancellation", t, func() {
+		convey.So(scrapeErr, convey.ShouldEqual, context.Canceled)
+		convey.So(remaining, convey.ShouldBeLessThan, 16)
+	})
+}
+
+func TestScrapeSysUserSummaryUniqueHosts(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "0", "0", "0", "0", "0", "0", "0", "1").
+		AddRow("batch", "0", "0", "0", "0", "0", "0", "0", "3").
+		AddRow("admin", "0", "0", "0", "0", "0", "0", "0", "12").
+		AddRow(nil, "0", "0", "0", "0", "0", "0", "0", "0")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	uniqueHosts := map[string]MetricResult{}
+	for m := range ch {
+		if m.Desc() == sysUserSummaryUniqueHosts {
+			got := readMetric(m)
+			uniqueHosts[got.labels["user"]] = got
+		}
+	}
+
+	convey.Convey("Unique hosts per user", t, func() {
+		convey.So(uniqueHosts, convey.ShouldResemble, map[string]MetricResult{
+			"app":        {labels: labelMap{"user": "app"}, value: 1, metricType: dto.MetricType_GAUGE},
+			"batch":      {labels: labelMap{"user": "batch"}, value: 3, metricType: dto.MetricType_GAUGE},
+			"admin":      {labels: labelMap{"user": "admin"}, value: 12, metricType: dto.MetricType_GAUGE},
+			"background": {labels: labelMap{"user": "background"}, value: 0, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryMariaDB(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// MariaDB before 10.6 has no sys schema, the scraper must not query it.
+	ctx := withServerVersion(context.Background(), parseServerVersion("10.3.38-MariaDB", ""))
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics on MariaDB 10.3", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryMissingSchema(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	missing := &mysqldriver.MySQLError{Number: 1146, Message: "Table 'sys.x$user_summary' doesn't exist"}
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnError(missing)
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnError(missing)
+
+	convey.Convey("Missing sys schema is not an error", t, func() {
+		convey.So(isTableMissing(missing), convey.ShouldBeTrue)
+		convey.So(isTableMissing(&mysqldriver.MySQLError{Number: 1045}), convey.ShouldBeFalse)
+		convey.So(isTableMissing(fmt.Errorf("Table doesn't exist")), convey.ShouldBeFalse)
+
+		for i := 0; i < 2; i++ {
+			ch := make(chan prometheus.Metric)
+			go func() {
+				err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch)
+				close(ch)
+			}()
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+			convey.So(err, convey.ShouldBeNil)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryColumnDrift(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// Columns are reordered, file_io_latency is missing and unknown columns are added.
+	columns := []string{"user", "total_connections", "statements", "statement_avg_latency", "statement_latency", "current_memory"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "70", "10", "200000000000", "2000000000000", "1048576")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"user": "app"}, value: 70, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 2, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Only known columns are exported", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryMaxExecutionTime(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.max_execution_time_ms", "5000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements"}
+	mock.ExpectQuery("^" + regexp.QuoteMeta("SELECT /*+ MAX_EXECUTION_TIME(5000) */ * FROM `sys`.`x$user_summary`")).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryMetricsAllowlist(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.metrics", "statement_latency, current_connections",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "10", "2000000000000", "3", "40", "5000000000000", "6", "70", "2")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var names []string
+	for m := range ch {
+		names = append(names, m.Desc().String())
+	}
+	convey.Convey("Only selected metrics are exported", t, func() {
+		convey.So(names, convey.ShouldResemble, []string{
+			sysUserSummaryStatementLatency.String(),
+			sysUserSummaryCurrentConnections.String(),
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+
+	convey.Convey("Unknown metrics are rejected", t, func() {
+		_, err := sysUserSummaryEnabledColumns("statements,rows_examined")
+		convey.So(err, convey.ShouldBeError, `unknown sys.user_summary metric "rows_examined"`)
+	})
+}
+
+func TestScrapeSysUserSummaryNormalizeLabels(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.normalize_labels",
+		"--collect.sys.user_summary.metrics", "statement_latency,current_connections",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app@10.0.0.1", "1", "1000000000000", "1", "1", "1", "2", "1", "1").
+		AddRow(" root ", "1", "500000000000", "1", "1", "1", "1", "1", "1").
+		AddRow("app@10.0.0.2", "1", "3000000000000", "1", "1", "1", "4", "1", "1")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"user": "app"}, value: 4, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "app"}, value: 6, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "root"}, value: 0.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"user": "root"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Accounts of the same user are summed", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeSysUserSummaryUntyped(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.untyped",
+		"--collect.sys.user_summary.metrics", "statements,statement_latency,current_connections",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"user", "statements", "statement_latency", "current_connections"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "10", "2000000000000", "6")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"user": "app"}, value: 10, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"user": "app"}, value: 2, metricType: dto.MetricType_UNTYPED},
+		{labels: labelMap{"user": "app"}, value: 6, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Counters are untyped", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestNormalizeSysUser(t *testing.T) {
+	convey.Convey("Account names are normalized", t, func() {
+		for account, user := range map[string]string{
+			"app":               "app",
+			" app\t":            "app",
+			"app@10.0.0.1":      "app",
+			"app@%":             "app",
+			"'app'@'localhost'": "app",
+			"`app` @ `%`":       "app",
+			"svc@corp@10.0.0.3": "svc@corp",
+			"@10.0.0.1":         "",
+			"background":        "background",
+		} {
+			convey.So(normalizeSysUser(account), convey.ShouldEqual, user)
+		}
+	})
+}
+
+func TestScrapeSysUserSummaryDerivedLatency(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.sys.user_summary.derived_latency",
+		"--collect.sys.user_summary.metrics", "current_connections",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	maxLatencyRows := sqlmock.NewRows([]string{"USER", "MAX(MAX_TIMER_WAIT)"}).
+		AddRow("app", "2500000000000").
+		AddRow(nil, "1000000")
+	mock.ExpectQuery(sanitizeQuery(sysUserSummaryMaxLatencyQuery)).WillReturnRows(maxLatencyRows)
+	columns := []string{"user", "statements", "statement_latency", "table_scans", "file_ios", "file_io_latency", "current_connections", "total_connections", "unique_hosts"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "4", "6000000000000", "0", "0", "0", "2", "5", "1").
+		AddRow("idle", "0", "0", "0", "0", "0", "1", "1", "1")
+	mock.ExpectQuery(sanitizeQuery(fmt.Sprintf(sysUserSummaryQuery, "sys"))).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSysUserSummary{}).Scrape(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"user": "app"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app"}, value: 1.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app"}, value: 2.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "idle"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "idle"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Average and maximum latency are derived", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
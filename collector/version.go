@@ -0,0 +1,120 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Server flavors.
+const (
+	flavorMySQL   = "mysql"
+	flavorMariaDB = "mariadb"
+	flavorPercona = "percona"
+)
+
+var (
+	versionRE      = regexp.MustCompile(`^\d+\.\d+`)
+	versionPartsRE = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+)
+
+// serverVersion describes the server the exporter is connected to.
+type serverVersion struct {
+	// Version is the raw @@version string, e.g. "10.6.12-MariaDB".
+	Version string
+	// Comment is the raw @@version_comment string.
+	Comment string
+	Flavor  string
+	Major   int
+	Minor   int
+	Patch   int
+}
+
+// parseServerVersion parses the @@version and @@version_comment of the server.
+func parseServerVersion(version, comment string) serverVersion {
+	v := serverVersion{
+		Version: version,
+		Comment: comment,
+		Flavor:  flavorMySQL,
+	}
+
+	lowerVersion, lowerComment := strings.ToLower(version), strings.ToLower(comment)
+	switch {
+	case strings.Contains(lowerVersion, "mariadb") || strings.Contains(lowerComment, "mariadb"):
+		v.Flavor = flavorMariaDB
+		// MariaDB may prefix the version for compatibility with old MySQL clients.
+		version = strings.TrimPrefix(version, "5.5.5-")
+	case strings.Contains(lowerComment, "percona"):
+		v.Flavor = flavorPercona
+	}
+
+	if match := versionPartsRE.FindStringSubmatch(version); match != nil {
+		v.Major, _ = strconv.Atoi(match[1])
+		v.Minor, _ = strconv.Atoi(match[2])
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+	return v
+}
+
+// number returns the version as a float comparable with Scraper.Version().
+func (v serverVersion) number() float64 {
+	version := v.Version
+	if v.Flavor == flavorMariaDB {
+		version = strings.TrimPrefix(version, "5.5.5-")
+	}
+	versionNum, _ := strconv.ParseFloat(versionRE.FindString(version), 64)
+	// If we can't match/parse the version, set it some big value that matches all versions.
+	if versionNum == 0 {
+		versionNum = 999
+	}
+	return versionNum
+}
+
+// release returns the version as major.minor.patch without the suffixes of
+// the distribution, e.g. "10.3.38" for "5.5.5-10.3.38-MariaDB-0ubuntu0.20.04.1",
+// or an empty string if the version could not be parsed.
+func (v serverVersion) release() string {
+	if v.Major == 0 && v.Minor == 0 {
+		return ""
+	}
+	return strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+}
+
+// atLeast reports whether the version is major.minor.patch or newer.
+func (v serverVersion) atLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+type serverVersionKey struct{}
+
+// withServerVersion returns a context carrying the server version, so that
+// scrapers can pick the right query for the server they are talking to.
+func withServerVersion(ctx context.Context, v serverVersion) context.Context {
+	return context.WithValue(ctx, serverVersionKey{}, v)
+}
+
+// serverVersionFromContext returns the server version detected for the scrape, if any.
+func serverVersionFromContext(ctx context.Context) (serverVersion, bool) {
+	v, ok := ctx.Value(serverVersionKey{}).(serverVersion)
+	return v, ok
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	convey.Convey("Server version parsing", t, func() {
+		for _, tt := range []struct {
+			version, comment string
+			flavor           string
+			major, minor     int
+			patch            int
+			number           float64
+			release          string
+		}{
+			{"10.6.12-MariaDB", "mariadb.org binary distribution", flavorMariaDB, 10, 6, 12, 10.6, "10.6.12"},
+			{"5.5.5-10.3.38-MariaDB-0ubuntu0.20.04.1", "Ubuntu 20.04", flavorMariaDB, 10, 3, 38, 10.3, "10.3.38"},
+			{"8.0.33", "MySQL Community Server - GPL", flavorMySQL, 8, 0, 33, 8.0, "8.0.33"},
+			{"5.7.42-log", "MySQL Community Server (GPL)", flavorMySQL, 5, 7, 42, 5.7, "5.7.42"},
+			{"5.7.42-46-log", "Percona Server (GPL), Release 46, Revision e1f7e9c", flavorPercona, 5, 7, 42, 5.7, "5.7.42"},
+			{"8.0", "", flavorMySQL, 8, 0, 0, 8.0, "8.0.0"},
+			{"", "", flavorMySQL, 0, 0, 0, 999, ""},
+		} {
+			v := parseServerVersion(tt.version, tt.comment)
+			convey.So(v.Flavor, convey.ShouldEqual, tt.flavor)
+			convey.So(v.Major, convey.ShouldEqual, tt.major)
+			convey.So(v.Minor, convey.ShouldEqual, tt.minor)
+			convey.So(v.Patch, convey.ShouldEqual, tt.patch)
+			convey.So(v.number(), convey.ShouldEqual, tt.number)
+			convey.So(v.release(), convey.ShouldEqual, tt.release)
+		}
+	})
+
+	convey.Convey("Server version in context", t, func() {
+		_, ok := serverVersionFromContext(context.Background())
+		convey.So(ok, convey.ShouldBeFalse)
+
+		ctx := withServerVersion(context.Background(), parseServerVersion("8.0.33", ""))
+		v, ok := serverVersionFromContext(ctx)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(v.atLeast(8, 0, 22), convey.ShouldBeTrue)
+		convey.So(v.atLeast(8, 0, 34), convey.ShouldBeFalse)
+		convey.So(v.atLeast(5, 7, 99), convey.ShouldBeTrue)
+		convey.So(v.atLeast(8, 1, 0), convey.ShouldBeFalse)
+	})
+}
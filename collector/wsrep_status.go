@@ -0,0 +1,136 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW GLOBAL STATUS LIKE 'wsrep_%'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	wsrepStatusQuery = `SHOW GLOBAL STATUS LIKE 'wsrep_%'`
+	// Subsystem.
+	galera = "galera"
+)
+
+// wsrepLocalStates maps wsrep_local_state_comment to the numeric node state,
+// see https://galeracluster.com/library/documentation/node-states.html.
+var wsrepLocalStates = map[string]float64{
+	"Initialized": 0,
+	"Joining":     1,
+	"Donor":       2,
+	"Desynced":    2,
+	"Joined":      3,
+	"Synced":      4,
+}
+
+// Metric descriptors.
+var (
+	galeraClusterSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "cluster_size"),
+		"The number of nodes in the Galera cluster.",
+		nil, nil,
+	)
+	galeraLocalStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "local_state"),
+		"The state of the node: 0 Initialized, 1 Joining, 2 Donor/Desynced, 3 Joined, 4 Synced.",
+		nil, nil,
+	)
+	galeraCertDepsDistanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "cert_deps_distance"),
+		"The average distance between the lowest and highest sequence number that can be applied in parallel.",
+		nil, nil,
+	)
+	galeraFlowControlPausedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "flow_control_paused"),
+		"The fraction of time replication was paused due to flow control since the last FLUSH STATUS.",
+		nil, nil,
+	)
+	galeraReadyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "ready"),
+		"Whether the node accepts queries.",
+		nil, nil,
+	)
+)
+
+// ScrapeWsrepStatus collects the Galera wsrep status of PXC and MariaDB Galera nodes.
+type ScrapeWsrepStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeWsrepStatus) Name() string {
+	return "wsrep_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeWsrepStatus) Help() string {
+	return "Collect Galera cluster metrics from SHOW GLOBAL STATUS LIKE 'wsrep_%'"
+}
+
+// Version of MySQL from which scraper is available. Support depends on the
+// flavor instead, Oracle MySQL is skipped in Scrape.
+func (ScrapeWsrepStatus) Version() float64 {
+	return 5.1
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeWsrepStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	if v, ok := serverVersionFromContext(ctx); ok && v.Flavor == flavorMySQL {
+		return nil
+	}
+	wsrepStatusRows, err := queryContext(ctx, db, wsrepStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer wsrepStatusRows.Close()
+
+	var key string
+	var val sql.RawBytes
+	for wsrepStatusRows.Next() {
+		if err := wsrepStatusRows.Scan(&key, &val); err != nil {
+			if skipScanError(ctx, err) {
+				continue
+			}
+			return err
+		}
+		var desc *prometheus.Desc
+		switch key {
+		case "wsrep_cluster_size":
+			desc = galeraClusterSizeDesc
+		case "wsrep_cert_deps_distance":
+			desc = galeraCertDepsDistanceDesc
+		case "wsrep_flow_control_paused":
+			desc = galeraFlowControlPausedDesc
+		case "wsrep_ready":
+			desc = galeraReadyDesc
+		case "wsrep_local_state_comment":
+			if state, ok := wsrepLocalStates[string(val)]; ok {
+				ch <- prometheus.MustNewConstMetric(galeraLocalStateDesc, prometheus.GaugeValue, state)
+			}
+			continue
+		default:
+			continue
+		}
+		if value, ok := parseStatus(val); ok {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+		}
+	}
+	return wsrepStatusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeWsrepStatus{}
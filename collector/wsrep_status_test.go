@@ -0,0 +1,111 @@
+// Copyright 2019 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeWsrepStatus(t *testing.T) {
+	for _, tt := range []struct {
+		comment string
+		state   float64
+	}{
+		{"Joining", 1},
+		{"Donor", 2},
+		{"Desynced", 2},
+		{"Joined", 3},
+		{"Synced", 4},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		columns := []string{"Variable_name", "Value"}
+		rows := sqlmock.NewRows(columns).
+			AddRow("wsrep_local_state_uuid", "e2c9a15e-5485-11e0-0800-6bbb637e7211").
+			AddRow("wsrep_cert_deps_distance", "23.88889").
+			AddRow("wsrep_flow_control_paused", "0.184353").
+			AddRow("wsrep_local_state", "4").
+			AddRow("wsrep_local_state_comment", tt.comment).
+			AddRow("wsrep_cluster_size", "3").
+			AddRow("wsrep_ready", "ON")
+		mock.ExpectQuery(sanitizeQuery(wsrepStatusQuery)).WillReturnRows(rows)
+
+		ctx := withServerVersion(context.Background(), parseServerVersion("5.7.25-28-57", "Percona XtraDB Cluster (GPL), Release rel28, Revision a2ef85f, WSREP version 31.35, wsrep_31.35"))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeWsrepStatus{}).Scrape(ctx, db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		metricExpected := []MetricResult{
+			{labels: labelMap{}, value: 23.88889, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 0.184353, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: tt.state, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		}
+		convey.Convey("Metrics comparison for "+tt.comment, t, func() {
+			for _, expect := range metricExpected {
+				got := readMetric(<-ch)
+				convey.So(got, convey.ShouldResemble, expect)
+			}
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestScrapeWsrepStatusMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	ctx := withServerVersion(context.Background(), parseServerVersion("8.0.33", "MySQL Community Server - GPL"))
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeWsrepStatus{}).Scrape(ctx, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No query on Oracle MySQL", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
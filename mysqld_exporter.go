@@ -17,18 +17,28 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
 	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/log"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/version"
 	"gopkg.in/alecthomas/kingpin.v2"
 	"gopkg.in/ini.v1"
@@ -45,6 +55,26 @@ var (
 		"web.telemetry-path",
 		"Path under which to expose metrics.",
 	).Default("/metrics").String()
+	enableCollectorsAPI = kingpin.Flag(
+		"web.enable-collectors-api",
+		"Serve /collectors to list and enable or disable collectors at runtime.",
+	).Default("false").Bool()
+	metricsExclude = kingpin.Flag(
+		"metrics.exclude",
+		"Comma separated list of metric names to drop from the scrape results, e.g. mysql_sys_user_table_scans_total. Names are matched after the --metrics.namespace rename.",
+	).Default("").String()
+	webConfigFile = kingpin.Flag(
+		"web.config.file",
+		"Path to a web configuration file in the exporter-toolkit format to serve over TLS and with basic authentication.",
+	).Default("").String()
+	metricsNamespace = kingpin.Flag(
+		"metrics.namespace",
+		"Namespace prefix to expose MySQL metrics under, replacing the default mysql prefix.",
+	).Default(defaultNamespace).String()
+	metricsConstLabels = kingpin.Flag(
+		"metrics.const-labels",
+		"Comma separated list of label=value pairs to add to all MySQL metrics, e.g. role=primary,cluster=eu-1.",
+	).Default("").String()
 	timeoutOffset = kingpin.Flag(
 		"timeout-offset",
 		"Offset to subtract from timeout in seconds.",
@@ -57,42 +87,134 @@ var (
 		"tls.insecure-skip-verify",
 		"Ignore certificate and server verification when using a tls connection.",
 	).Bool()
-	dsn string
+	mysqlSSLCA = kingpin.Flag(
+		"mysql.ssl-ca",
+		"Path to the CA file used to verify the MySQL server certificate.",
+	).String()
+	mysqlSSLCert = kingpin.Flag(
+		"mysql.ssl-cert",
+		"Path to the client certificate used for TLS client authentication.",
+	).String()
+	mysqlSSLKey = kingpin.Flag(
+		"mysql.ssl-key",
+		"Path to the client key used for TLS client authentication.",
+	).String()
+	mysqlSocket = kingpin.Flag(
+		"mysql.socket",
+		"Path to the UNIX socket to connect to MySQL with, instead of TCP.",
+	).String()
+	mysqlCloudSQLInstance = kingpin.Flag(
+		"mysql.cloud-sql-instance",
+		"Cloud SQL instance connection name, as project:region:instance, to connect to through the UNIX socket a separately running Cloud SQL proxy creates for it in --mysql.cloud-sql-socket-dir, instead of the host in the dsn.",
+	).String()
+	mysqlCloudSQLSocketDir = kingpin.Flag(
+		"mysql.cloud-sql-socket-dir",
+		"Directory in which the Cloud SQL proxy creates the UNIX sockets of the instances.",
+	).Default("/cloudsql").String()
+	mysqlCharset = kingpin.Flag(
+		"mysql.charset",
+		"Character set of the connections to MySQL, with its _general_ci collation, unless the dsn sets charset or collation. Empty to use the driver default.",
+	).Default("utf8mb4").String()
+	mysqlAuthMode = kingpin.Flag(
+		"mysql.auth-mode",
+		"How to authenticate to MySQL: password uses the password of the dsn, rds-iam generates an AWS RDS IAM authentication token for each connection, signed with the credentials of the AWS SDK default credential chain.",
+	).Default(authModePassword).Enum(authModePassword, authModeRDSIAM)
+	mysqlConnectRetries = kingpin.Flag(
+		"mysql.connect-retries",
+		"Number of times to retry connecting to MySQL at startup with exponential backoff before scraping it, mysql_up 0 is served meanwhile. 0 to not wait for MySQL.",
+	).Default("0").Int()
+	mysqlConnectTimeout = kingpin.Flag(
+		"mysql.connect-timeout",
+		"Timeout of each connection attempt to MySQL at startup.",
+	).Default("5s").Duration()
+	probeAllowedTargets = kingpin.Flag(
+		"probe.allowed-targets",
+		"Comma separated list of host:port MySQL servers that may be scraped through /probe, /probe is disabled when empty.",
+	).Default("").String()
+	dsn             string
+	constLabels     prometheus.Labels
+	excludedMetrics map[string]bool
+	// newCredentialProvider returns the provider of the passwords to
+	// connect to a dsn with, if the password of the dsn is not used.
+	newCredentialProvider func(dsn string) (collector.CredentialProvider, error)
 )
 
+// Backoff between connection attempts at startup.
+const (
+	connectInitialBackoff = time.Second
+	connectMaxBackoff     = 30 * time.Second
+)
+
+// defaultNamespace is the namespace used by all collectors.
+const defaultNamespace = "mysql"
+
 // scrapers lists all possible collection methods and if they should be enabled by default.
 var scrapers = map[collector.Scraper]bool{
 	collector.ScrapeGlobalStatus{}:                        true,
 	collector.ScrapeGlobalVariables{}:                     true,
+	collector.ScrapeServerInfo{}:                          false,
 	collector.ScrapeSlaveStatus{}:                         true,
 	collector.ScrapeProcesslist{}:                         false,
 	collector.ScrapeUser{}:                                false,
 	collector.ScrapeTableSchema{}:                         false,
 	collector.ScrapeInfoSchemaInnodbTablespaces{}:         false,
 	collector.ScrapeInnodbMetrics{}:                       false,
+	collector.ScrapeInnodbTrx{}:                           false,
 	collector.ScrapeAutoIncrementColumns{}:                false,
+	collector.ScrapeForeignKeys{}:                         false,
 	collector.ScrapeBinlogSize{}:                          false,
+	collector.ScrapeMasterStatus{}:                        false,
+	collector.ScrapeOpenTables{}:                          false,
 	collector.ScrapePerfTableIOWaits{}:                    false,
 	collector.ScrapePerfIndexIOWaits{}:                    false,
 	collector.ScrapePerfTableLockWaits{}:                  false,
 	collector.ScrapePerfEventsStatements{}:                false,
 	collector.ScrapePerfEventsStatementsSum{}:             false,
+	collector.ScrapeSchemaStatementSummary{}:              false,
+	collector.ScrapePerfStatementsByUser{}:                false,
+	collector.ScrapeErrorsSummary{}:                       false,
+	collector.ScrapeStatementHistogram{}:                  false,
 	collector.ScrapePerfEventsWaits{}:                     false,
+	collector.ScrapePerfMemoryGlobal{}:                    false,
+	collector.ScrapeHostCache{}:                           false,
+	collector.ScrapeThreadsByType{}:                       false,
+	collector.ScrapeSetupInstruments{}:                    false,
+	collector.ScrapeSessionStatus{}:                       false,
+	collector.ScrapePreparedStatements{}:                  false,
+	collector.ScrapeVariablesByThread{}:                   false,
+	collector.ScrapePerfStagesByAccount{}:                 false,
+	collector.ScrapeDataLocks{}:                           false,
 	collector.ScrapePerfFileEvents{}:                      false,
 	collector.ScrapePerfFileInstances{}:                   false,
 	collector.ScrapePerfReplicationGroupMemberStats{}:     false,
+	collector.ScrapeGroupReplicationMembers{}:             false,
 	collector.ScrapePerfReplicationApplierStatsByWorker{}: false,
+	collector.ScrapeApplierCoordinator{}:                  false,
 	collector.ScrapeUserStat{}:                            false,
 	collector.ScrapeClientStat{}:                          false,
 	collector.ScrapeTableStat{}:                           false,
 	collector.ScrapeSchemaStat{}:                          false,
 	collector.ScrapeInnodbCmp{}:                           true,
 	collector.ScrapeInnodbCmpMem{}:                        true,
+	collector.ScrapeBufferPoolStats{}:                     false,
 	collector.ScrapeQueryResponseTime{}:                   true,
 	collector.ScrapeEngineTokudbStatus{}:                  false,
 	collector.ScrapeEngineInnodbStatus{}:                  false,
+	collector.ScrapeEngineInnodbDeadlocks{}:               false,
+	collector.ScrapeInnodbStatusBufferPool{}:              false,
+	collector.ScrapeInnodbRedoLog{}:                       false,
 	collector.ScrapeHeartbeat{}:                           false,
 	collector.ScrapeSlaveHosts{}:                          false,
+	collector.ScrapeSysUserSummary{}:                      false,
+	collector.ScrapeSysUserSummaryByStatementType{}:       false,
+	collector.ScrapeSysHostSummaryByFileIO{}:              false,
+	collector.ScrapeSysMemoryByThread{}:                   false,
+	collector.ScrapeStatementAnalysis{}:                   false,
+	collector.ScrapeSysStatementsWithErrors{}:             false,
+	collector.ScrapeUnusedIndexes{}:                       false,
+	collector.ScrapeSchemaTableLockWaits{}:                false,
+	collector.ScrapeWsrepStatus{}:                         false,
+	collector.ScrapeAuroraReplicaStatus{}:                 false,
 }
 
 func parseMycnf(config interface{}) (string, error) {
@@ -100,6 +222,9 @@ func parseMycnf(config interface{}) (string, error) {
 	opts := ini.LoadOptions{
 		// MySQL ini file can have boolean keys.
 		AllowBooleanKeys: true,
+		// Only treat "#" preceded by a space as an inline comment, so that
+		// passwords can contain it.
+		SpaceBeforeInlineComment: true,
 	}
 	cfg, err := ini.LoadSources(opts, config)
 	if err != nil {
@@ -133,17 +258,277 @@ func parseMycnf(config interface{}) (string, error) {
 	return dsn, nil
 }
 
-func customizeTLS(sslCA string, sslCert string, sslKey string) error {
-	var tlsCfg tls.Config
-	caBundle := x509.NewCertPool()
-	pemCA, err := ioutil.ReadFile(sslCA)
+// addSocketFlag points the dsn at the --mysql.socket UNIX socket. The socket
+// takes precedence over the default local TCP address, but a dsn explicitly
+// pointing at a remote host is rejected as conflicting.
+func addSocketFlag(dsn string, socket string) (string, error) {
+	if socket == "" {
+		return dsn, nil
+	}
+	cfg, err := mysql.ParseDSN(dsn)
 	if err != nil {
-		return err
+		return dsn, fmt.Errorf("failed to parse mysql dsn: %s", err)
 	}
-	if ok := caBundle.AppendCertsFromPEM(pemCA); ok {
-		tlsCfg.RootCAs = caBundle
-	} else {
-		return fmt.Errorf("failed parse pem-encoded CA certificates from %s", sslCA)
+	if cfg.Net == "tcp" && !isLocalAddr(cfg.Addr) {
+		return dsn, fmt.Errorf("--mysql.socket conflicts with host %s in the mysql dsn", cfg.Addr)
+	}
+	cfg.Net = "unix"
+	cfg.Addr = socket
+	return cfg.FormatDSN(), nil
+}
+
+// addCharsetFlag sets the charset of the dsn and its general collation, so
+// that user names and statements are not mangled by servers defaulting to
+// latin1. A charset or collation already in the dsn is kept. As the flag is
+// set by default, a dsn the driver cannot parse is kept as is and reported
+// by the scrapes instead of failing at startup.
+func addCharsetFlag(dsn string, charset string) string {
+	if charset == "" {
+		return dsn
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return dsn
+	}
+	if _, ok := cfg.Params["charset"]; ok || strings.Contains(dsn, "collation=") {
+		return dsn
+	}
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["charset"] = charset
+	cfg.Collation = charset + "_general_ci"
+	return cfg.FormatDSN()
+}
+
+// cloudSQLNetwork is the network of the dsn registered with the driver to dial
+// the Cloud SQL proxy sockets. The driver itself registers "cloudsql" on App
+// Engine, which must not be overridden.
+const cloudSQLNetwork = "cloudsql-proxy"
+
+// addCloudSQLFlag points the dsn at the --mysql.cloud-sql-instance instance
+// and registers the dialer of the cloudSQLNetwork network, which connects to
+// the socket of the instance in socketDir. Like addSocketFlag, a dsn with a
+// socket or explicitly pointing at a remote host is rejected as conflicting.
+func addCloudSQLFlag(dsn string, instance string, socket string, socketDir string) (string, error) {
+	if instance == "" {
+		return dsn, nil
+	}
+	if socket != "" {
+		return dsn, fmt.Errorf("--mysql.cloud-sql-instance conflicts with --mysql.socket")
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return dsn, fmt.Errorf("failed to parse mysql dsn: %s", err)
+	}
+	switch {
+	case cfg.Net == "unix":
+		return dsn, fmt.Errorf("--mysql.cloud-sql-instance conflicts with socket %s in the mysql dsn", cfg.Addr)
+	case cfg.Net == "tcp" && !isLocalAddr(cfg.Addr):
+		return dsn, fmt.Errorf("--mysql.cloud-sql-instance conflicts with host %s in the mysql dsn", cfg.Addr)
+	}
+	mysql.RegisterDial(cloudSQLNetwork, cloudSQLDialer(socketDir))
+	cfg.Net = cloudSQLNetwork
+	cfg.Addr = instance
+	return cfg.FormatDSN(), nil
+}
+
+// cloudSQLDialer returns a dialer connecting to the socket named after the
+// instance connection name in socketDir.
+func cloudSQLDialer(socketDir string) mysql.DialFunc {
+	return func(instance string) (net.Conn, error) {
+		return net.Dial("unix", filepath.Join(socketDir, instance))
+	}
+}
+
+// isLocalAddr reports whether a TCP address refers to the local host.
+func isLocalAddr(addr string) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// Authentication modes of --mysql.auth-mode.
+const (
+	authModePassword = "password"
+	authModeRDSIAM   = "rds-iam"
+)
+
+// addRDSIAMAuth prepares the dsn for RDS IAM authentication: tokens are
+// sent as cleartext passwords, which is only allowed over TLS.
+func addRDSIAMAuth(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse mysql dsn: %s", err)
+	}
+	if cfg.Net != "tcp" {
+		return "", fmt.Errorf("--mysql.auth-mode=%s needs a tcp dsn, got %q", authModeRDSIAM, cfg.Net)
+	}
+	if cfg.TLSConfig == "" || cfg.TLSConfig == "false" {
+		return "", fmt.Errorf("--mysql.auth-mode=%s needs TLS, set tls in the dsn or --mysql.ssl-ca", authModeRDSIAM)
+	}
+	cfg.AllowCleartextPasswords = true
+	return cfg.FormatDSN(), nil
+}
+
+// credentialProvider returns the provider of the passwords to connect to the
+// dsn with, nil to use the password of the dsn.
+func credentialProvider(dsn string) (collector.CredentialProvider, error) {
+	if newCredentialProvider == nil {
+		return nil, nil
+	}
+	return newCredentialProvider(dsn)
+}
+
+// rdsIAMProvider generates RDS IAM authentication tokens for the user and
+// endpoint of a dsn, signed with the AWS credentials of the session.
+type rdsIAMProvider struct {
+	endpoint, user, region string
+	credentials            *credentials.Credentials
+}
+
+// newRDSIAMSession returns the AWS session of the RDS IAM token providers,
+// which resolves credentials and region like the AWS CLI does. It is shared
+// by all scrapes so that temporary credentials are cached until they expire.
+func newRDSIAMSession() (*session.Session, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %s", err)
+	}
+	return sess, nil
+}
+
+// newRDSIAMProvider returns a provider of RDS IAM authentication tokens for
+// the dsn. The region is that of the session, or else of the RDS endpoint of
+// the dsn.
+func newRDSIAMProvider(dsn string, sess *session.Session) (collector.CredentialProvider, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mysql dsn: %s", err)
+	}
+	region := aws.StringValue(sess.Config.Region)
+	if region == "" {
+		region = rdsEndpointRegion(cfg.Addr)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("--mysql.auth-mode=%s needs AWS_REGION to be set for endpoint %s", authModeRDSIAM, cfg.Addr)
+	}
+	return &rdsIAMProvider{endpoint: cfg.Addr, user: cfg.User, region: region, credentials: sess.Config.Credentials}, nil
+}
+
+// GetPassword implements collector.CredentialProvider.
+func (p *rdsIAMProvider) GetPassword(ctx context.Context) (string, error) {
+	return rdsutils.BuildAuthToken(p.endpoint, p.region, p.user, p.credentials)
+}
+
+// rdsEndpointRegion returns the region of an RDS endpoint such as
+// mydb.c9akciq32.eu-west-1.rds.amazonaws.com:3306, or an empty string.
+func rdsEndpointRegion(endpoint string) string {
+	host := endpoint
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	labels := strings.Split(host, ".")
+	for i := 1; i+1 < len(labels); i++ {
+		if labels[i] == "rds" && labels[i+1] == "amazonaws" {
+			return labels[i-1]
+		}
+	}
+	return ""
+}
+
+// pinger is the part of *sql.DB used to check the connection.
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// pingWithRetry pings db up to retries+1 times, doubling the wait between
+// attempts starting from backoff up to connectMaxBackoff. Each attempt is
+// bounded by timeout. It returns the error of the last attempt.
+func pingWithRetry(ctx context.Context, db pinger, retries int, timeout time.Duration, backoff time.Duration) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = db.PingContext(pingCtx)
+		cancel()
+		if err == nil || attempt >= retries {
+			return err
+		}
+		log.Infof("MySQL is not reachable yet (attempt %d of %d), retrying in %s: %s", attempt+1, retries+1, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > connectMaxBackoff {
+			backoff = connectMaxBackoff
+		}
+	}
+}
+
+// waitForMySQL retries connecting to MySQL at startup. Until it returns,
+// once MySQL is reachable or the retries are exhausted, the metrics handler
+// only reports mysql_up 0.
+func waitForMySQL(dsn string) {
+	provider, err := credentialProvider(dsn)
+	if err != nil {
+		log.Errorln("Error getting MySQL credentials:", err)
+		return
+	}
+	db, err := collector.OpenDB(dsn, provider)
+	if err != nil {
+		log.Errorln("Error opening connection to database:", err)
+		return
+	}
+	defer db.Close()
+	if err := pingWithRetry(context.Background(), db, *mysqlConnectRetries, *mysqlConnectTimeout, connectInitialBackoff); err != nil {
+		log.Errorf("MySQL is not reachable after %d attempts: %s", *mysqlConnectRetries+1, err)
+		return
+	}
+	log.Infoln("Connected to MySQL")
+}
+
+// addTLSFlags registers a custom TLS configuration from the --mysql.ssl-* flags
+// and enables it in the dsn.
+func addTLSFlags(dsn string, sslCA string, sslCert string, sslKey string) (string, error) {
+	if sslCA == "" && sslCert == "" && sslKey == "" {
+		return dsn, nil
+	}
+	if (sslCert == "") != (sslKey == "") {
+		return dsn, fmt.Errorf("--mysql.ssl-cert and --mysql.ssl-key must be specified together")
+	}
+	if err := customizeTLS(sslCA, sslCert, sslKey); err != nil {
+		return dsn, fmt.Errorf("failed to register a custom TLS configuration for mysql dsn: %s", err)
+	}
+	if strings.Contains(dsn, "tls=custom") {
+		return dsn, nil
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&tls=custom", nil
+	}
+	return dsn + "?tls=custom", nil
+}
+
+func customizeTLS(sslCA string, sslCert string, sslKey string) error {
+	var tlsCfg tls.Config
+	// Without a CA the system roots are used to verify the server.
+	if sslCA != "" {
+		caBundle := x509.NewCertPool()
+		pemCA, err := ioutil.ReadFile(sslCA)
+		if err != nil {
+			return err
+		}
+		if ok := caBundle.AppendCertsFromPEM(pemCA); ok {
+			tlsCfg.RootCAs = caBundle
+		} else {
+			return fmt.Errorf("failed parse pem-encoded CA certificates from %s", sslCA)
+		}
 	}
 	if sslCert != "" && sslKey != "" {
 		certPairs := make([]tls.Certificate, 0, 1)
@@ -160,69 +545,279 @@ func customizeTLS(sslCA string, sslCert string, sslKey string) error {
 	return nil
 }
 
+// serve serves handler on l, over TLS unless tlsCfg is nil.
+func serve(l net.Listener, handler http.Handler, tlsCfg *tls.Config) error {
+	server := &http.Server{Handler: handler, TLSConfig: tlsCfg}
+	if tlsCfg == nil {
+		return server.Serve(l)
+	}
+	return server.ServeTLS(l, "", "")
+}
+
 func init() {
 	prometheus.MustRegister(version.NewCollector("mysqld_exporter"))
 }
 
-func newHandler(metrics collector.Metrics, scrapers []collector.Scraper) http.HandlerFunc {
+// newHandler serves the metrics of the enabled scrapers once ready is
+// closed. Until then, MySQL is reported down without being scraped.
+func newHandler(metrics collector.Metrics, registry *collector.ScraperRegistry, ready <-chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-ready:
+			serveMetrics(w, r, dsn, metrics, registry.Enabled(), prometheus.Gatherers{prometheus.DefaultGatherer})
+		default:
+			metrics.MySQLUp.Set(0)
+			upRegistry := prometheus.NewRegistry()
+			prometheus.WrapRegistererWith(constLabels, upRegistry).MustRegister(metrics.MySQLUp)
+			gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, exporterGatherer(upRegistry)}
+			promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+		}
+	}
+}
+
+// newProbeHandler scrapes the MySQL server given by the target query
+// parameter, e.g. `/probe?target=db1:3306`, with the credentials of the
+// configured dsn. Only targets in allowedTargets are probed.
+func newProbeHandler(registry *collector.ScraperRegistry, allowedTargets []string) http.HandlerFunc {
+	allowed := make(map[string]bool, len(allowedTargets))
+	for _, target := range allowedTargets {
+		allowed[probeTargetAddr(target)] = true
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		filteredScrapers := scrapers
-		params := r.URL.Query()["collect[]"]
-		// Use request context for cancellation when connection gets closed.
-		ctx := r.Context()
-		// If a timeout is configured via the Prometheus header, add it to the context.
-		if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
-			timeoutSeconds, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				log.Errorf("Failed to parse timeout from Prometheus header: %s", err)
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "target parameter is missing", http.StatusBadRequest)
+			return
+		}
+		addr := probeTargetAddr(target)
+		if !allowed[addr] {
+			http.Error(w, fmt.Sprintf("target %q is not allowed", target), http.StatusForbidden)
+			return
+		}
+		targetDSN, err := probeDSN(dsn, addr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Exporter metrics are not shared between targets.
+		serveMetrics(w, r, targetDSN, collector.NewMetrics(), registry.Enabled(), nil)
+	}
+}
+
+// probeTargetAddr adds the default MySQL port to targets without a port.
+func probeTargetAddr(target string) string {
+	target = strings.TrimSpace(target)
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return net.JoinHostPort(target, "3306")
+	}
+	return target
+}
+
+// probeDSN points the dsn at the TCP address addr, keeping its credentials
+// and parameters.
+func probeDSN(dsn string, addr string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse mysql dsn: %s", err)
+	}
+	cfg.Net = "tcp"
+	cfg.Addr = addr
+	return cfg.FormatDSN(), nil
+}
+
+// serveMetrics runs the scrapers against the MySQL server of dsn and serves
+// their metrics along with the metrics of gatherers.
+func serveMetrics(w http.ResponseWriter, r *http.Request, dsn string, metrics collector.Metrics, scrapers []collector.Scraper, gatherers prometheus.Gatherers) {
+	filteredScrapers := scrapers
+	params := r.URL.Query()["collect[]"]
+	// Use request context for cancellation when connection gets closed.
+	ctx := r.Context()
+	// If a timeout is configured via the Prometheus header, add it to the context.
+	if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
+		timeoutSeconds, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Errorf("Failed to parse timeout from Prometheus header: %s", err)
+		} else {
+			if *timeoutOffset >= timeoutSeconds {
+				// Ignore timeout offset if it doesn't leave time to scrape.
+				log.Errorf(
+					"Timeout offset (--timeout-offset=%.2f) should be lower than prometheus scrape time (X-Prometheus-Scrape-Timeout-Seconds=%.2f).",
+					*timeoutOffset,
+					timeoutSeconds,
+				)
 			} else {
-				if *timeoutOffset >= timeoutSeconds {
-					// Ignore timeout offset if it doesn't leave time to scrape.
-					log.Errorf(
-						"Timeout offset (--timeout-offset=%.2f) should be lower than prometheus scrape time (X-Prometheus-Scrape-Timeout-Seconds=%.2f).",
-						*timeoutOffset,
-						timeoutSeconds,
-					)
-				} else {
-					// Subtract timeout offset from timeout.
-					timeoutSeconds -= *timeoutOffset
-				}
-				// Create new timeout context with request context as parent.
-				var cancel context.CancelFunc
-				ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
-				defer cancel()
-				// Overwrite request with timeout context.
-				r = r.WithContext(ctx)
+				// Subtract timeout offset from timeout.
+				timeoutSeconds -= *timeoutOffset
 			}
+			// Create new timeout context with request context as parent.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
+			defer cancel()
+			// Overwrite request with timeout context.
+			r = r.WithContext(ctx)
+		}
+	}
+	log.Debugln("collect query:", params)
+
+	// Check if we have some "collect[]" query parameters.
+	if len(params) > 0 {
+		filters := make(map[string]bool)
+		for _, param := range params {
+			filters[param] = true
 		}
-		log.Debugln("collect query:", params)
 
-		// Check if we have some "collect[]" query parameters.
-		if len(params) > 0 {
-			filters := make(map[string]bool)
-			for _, param := range params {
-				filters[param] = true
+		filteredScrapers = nil
+		for _, scraper := range scrapers {
+			if filters[scraper.Name()] {
+				filteredScrapers = append(filteredScrapers, scraper)
 			}
+		}
+	}
+
+	provider, err := credentialProvider(dsn)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	exporter := collector.New(ctx, dsn, metrics, filteredScrapers)
+	exporter.SetCredentialProvider(provider)
+	registry := prometheus.NewRegistry()
+	prometheus.WrapRegistererWith(constLabels, registry).MustRegister(exporter)
+
+	gatherers = append(gatherers, exporterGatherer(registry))
+	// Delegate http serving to Prometheus client library, which will call collector.Collect.
+	h := promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+	h.ServeHTTP(w, r)
+}
 
-			filteredScrapers = nil
-			for _, scraper := range scrapers {
-				if filters[scraper.Name()] {
-					filteredScrapers = append(filteredScrapers, scraper)
+// exporterGatherer applies --metrics.namespace and --metrics.exclude to the
+// MySQL metrics of registry.
+func exporterGatherer(registry prometheus.Gatherer) prometheus.Gatherer {
+	gatherer := registry
+	if *metricsNamespace != defaultNamespace {
+		gatherer = namespaceGatherer{Gatherer: gatherer, namespace: *metricsNamespace}
+	}
+	if len(excludedMetrics) > 0 {
+		gatherer = excludeGatherer{Gatherer: gatherer, excluded: excludedMetrics}
+	}
+	return gatherer
+}
+
+// newCollectorsHandler lists the collectors and whether they are enabled as
+// JSON. POST requests with enable and disable form values toggle collectors
+// by name, e.g. `curl -d disable=info_schema.tables localhost:9104/collectors`.
+func newCollectorsHandler(registry *collector.ScraperRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for _, name := range r.PostForm["enable"] {
+				if err := registry.Enable(name); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
 				}
+				log.Infof("Enabled collector %s", name)
 			}
+			for _, name := range r.PostForm["disable"] {
+				if err := registry.Disable(name); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				log.Infof("Disabled collector %s", name)
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(registry.Status()); err != nil {
+			log.Errorf("Failed to write collectors status: %s", err)
 		}
+	}
+}
 
-		registry := prometheus.NewRegistry()
-		registry.MustRegister(collector.New(ctx, dsn, metrics, filteredScrapers))
+// parseConstLabels parses the comma separated label=value pairs of
+// --metrics.const-labels.
+func parseConstLabels(list string) (prometheus.Labels, error) {
+	labels := prometheus.Labels{}
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid const label %q, expected label=value", pair)
+		}
+		name, value := strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return nil, fmt.Errorf("invalid const label name %q", name)
+		}
+		if _, ok := labels[name]; ok {
+			return nil, fmt.Errorf("duplicate const label %q", name)
+		}
+		labels[name] = value
+	}
+	return labels, nil
+}
 
-		gatherers := prometheus.Gatherers{
-			prometheus.DefaultGatherer,
-			registry,
+// namespaceGatherer exposes the metric families of the wrapped Gatherer under
+// another namespace than the default mysql namespace.
+type namespaceGatherer struct {
+	prometheus.Gatherer
+	namespace string
+}
+
+// Gather implements prometheus.Gatherer.
+func (g namespaceGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	for _, mf := range mfs {
+		if strings.HasPrefix(mf.GetName(), defaultNamespace+"_") {
+			name := g.namespace + strings.TrimPrefix(mf.GetName(), defaultNamespace)
+			mf.Name = &name
 		}
-		// Delegate http serving to Prometheus client library, which will call collector.Collect.
-		h := promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
-		h.ServeHTTP(w, r)
 	}
+	return mfs, err
+}
+
+// excludeGatherer drops the metric families of the wrapped Gatherer listed in
+// --metrics.exclude.
+type excludeGatherer struct {
+	prometheus.Gatherer
+	excluded map[string]bool
+}
+
+func (g excludeGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	kept := mfs[:0]
+	for _, mf := range mfs {
+		if !g.excluded[mf.GetName()] {
+			kept = append(kept, mf)
+		}
+	}
+	return kept, err
+}
+
+// parseExcludedMetrics parses the comma separated metric names of
+// --metrics.exclude.
+func parseExcludedMetrics(list string) (map[string]bool, error) {
+	excluded := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !model.IsValidMetricName(model.LabelValue(name)) {
+			return nil, fmt.Errorf("invalid metric name %q in --metrics.exclude", name)
+		}
+		excluded[name] = true
+	}
+	return excluded, nil
 }
 
 func main() {
@@ -248,6 +843,10 @@ func main() {
 	kingpin.HelpFlag.Short('h')
 	kingpin.Parse()
 
+	if !model.IsValidMetricName(model.LabelValue(*metricsNamespace + "_up")) {
+		log.Fatalf("Invalid metrics namespace: %q", *metricsNamespace)
+	}
+
 	// landingPage contains the HTML served at '/'.
 	// TODO: Make this nicer and more informative.
 	var landingPage = []byte(`<html>
@@ -269,22 +868,90 @@ func main() {
 			log.Fatal(err)
 		}
 	}
+	var err error
+	if constLabels, err = parseConstLabels(*metricsConstLabels); err != nil {
+		log.Fatal(err)
+	}
+	if excludedMetrics, err = parseExcludedMetrics(*metricsExclude); err != nil {
+		log.Fatal(err)
+	}
+	if dsn, err = addCloudSQLFlag(dsn, *mysqlCloudSQLInstance, *mysqlSocket, *mysqlCloudSQLSocketDir); err != nil {
+		log.Fatal(err)
+	}
+	if dsn, err = addSocketFlag(dsn, *mysqlSocket); err != nil {
+		log.Fatal(err)
+	}
+	if dsn, err = addTLSFlags(dsn, *mysqlSSLCA, *mysqlSSLCert, *mysqlSSLKey); err != nil {
+		log.Fatal(err)
+	}
+	dsn = addCharsetFlag(dsn, *mysqlCharset)
+	if *mysqlAuthMode == authModeRDSIAM {
+		if dsn, err = addRDSIAMAuth(dsn); err != nil {
+			log.Fatal(err)
+		}
+		sess, err := newRDSIAMSession()
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Check the region at startup rather than at the first scrape.
+		if _, err = newRDSIAMProvider(dsn, sess); err != nil {
+			log.Fatal(err)
+		}
+		newCredentialProvider = func(dsn string) (collector.CredentialProvider, error) {
+			return newRDSIAMProvider(dsn, sess)
+		}
+	}
 
-	// Register only scrapers enabled by flag.
+	// Enable only scrapers enabled by flag.
 	log.Infof("Enabled scrapers:")
-	enabledScrapers := []collector.Scraper{}
+	enabledScrapers := map[collector.Scraper]bool{}
 	for scraper, enabled := range scraperFlags {
 		if *enabled {
 			log.Infof(" --collect.%s", scraper.Name())
-			enabledScrapers = append(enabledScrapers, scraper)
 		}
+		enabledScrapers[scraper] = *enabled
+	}
+	registry := collector.NewScraperRegistry(enabledScrapers)
+	ready := make(chan struct{})
+	if *mysqlConnectRetries > 0 {
+		go func() {
+			waitForMySQL(dsn)
+			close(ready)
+		}()
+	} else {
+		close(ready)
 	}
-	handlerFunc := newHandler(collector.NewMetrics(), enabledScrapers)
+	handlerFunc := newHandler(collector.NewMetrics(), registry, ready)
 	http.Handle(*metricPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handlerFunc))
+	if *enableCollectorsAPI {
+		http.HandleFunc("/collectors", newCollectorsHandler(registry))
+	}
+	if *probeAllowedTargets != "" {
+		targets := strings.Split(*probeAllowedTargets, ",")
+		log.Infof("Serving /probe for %d targets", len(targets))
+		http.HandleFunc("/probe", newProbeHandler(registry, targets))
+	}
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write(landingPage)
 	})
 
-	log.Infoln("Listening on", *listenAddress)
-	log.Fatal(http.ListenAndServe(*listenAddress, nil))
+	var (
+		tlsCfg  *tls.Config
+		handler http.Handler = http.DefaultServeMux
+	)
+	if *webConfigFile != "" {
+		var users map[string]string
+		if tlsCfg, users, err = loadWebConfig(*webConfigFile); err != nil {
+			log.Fatal(err)
+		}
+		if len(users) > 0 {
+			handler = basicAuth(handler, users)
+		}
+	}
+	listener, err := net.Listen("tcp", *listenAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Infoln("Listening on", *listenAddress, "TLS:", tlsCfg != nil, "basic auth:", handler != http.DefaultServeMux)
+	log.Fatal(serve(listener, handler, tlsCfg))
 }
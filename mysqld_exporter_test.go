@@ -15,13 +15,24 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -29,7 +40,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/smartystreets/goconvey/convey"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/prometheus/mysqld_exporter/collector"
 )
 
 func TestParseMycnf(t *testing.T) {
@@ -75,6 +94,23 @@ func TestParseMycnf(t *testing.T) {
 			[mysql]
 			skip-auto-rehash
 		`
+		quotedConfig = `
+			[client]
+			user = "root"
+			password = "abc 123"
+		`
+		quotedConfig2 = `
+			[client]
+			user = root
+			password = 'p@ss#word'
+		`
+		commentConfig = `
+			# Credentials used by the exporter
+			[client]
+			; legacy comment style
+			user = root # inline comment
+			password = ab#c123
+		`
 		badConfig = `
 			[client]
 			user = root
@@ -115,6 +151,18 @@ func TestParseMycnf(t *testing.T) {
 			dsn, _ := parseMycnf([]byte(ignoreBooleanKeys))
 			convey.So(dsn, convey.ShouldEqual, "root:abc123@tcp(localhost:3306)/")
 		})
+		convey.Convey("Quoted password with spaces", func() {
+			dsn, _ := parseMycnf([]byte(quotedConfig))
+			convey.So(dsn, convey.ShouldEqual, "root:abc 123@tcp(localhost:3306)/")
+		})
+		convey.Convey("Quoted password with special characters", func() {
+			dsn, _ := parseMycnf([]byte(quotedConfig2))
+			convey.So(dsn, convey.ShouldEqual, "root:p@ss#word@tcp(localhost:3306)/")
+		})
+		convey.Convey("Comments", func() {
+			dsn, _ := parseMycnf([]byte(commentConfig))
+			convey.So(dsn, convey.ShouldEqual, "root:ab#c123@tcp(localhost:3306)/")
+		})
 		convey.Convey("Missed user", func() {
 			_, err := parseMycnf([]byte(badConfig))
 			convey.So(err, convey.ShouldBeError, fmt.Errorf("no user or password specified under [client] in %s", badConfig))
@@ -134,6 +182,688 @@ func TestParseMycnf(t *testing.T) {
 	})
 }
 
+// writeTestCertificate writes a self-signed certificate and its key into dir.
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mysqld_exporter-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestAddTLSFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mysqld_exporter-tls-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	convey.Convey("TLS flags", t, func() {
+		convey.Convey("No flags leave the dsn untouched", func() {
+			dsn, err := addTLSFlags("root@tcp(localhost:3306)/", "", "", "")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root@tcp(localhost:3306)/")
+		})
+		convey.Convey("Client certificate is registered as custom", func() {
+			dsn, err := addTLSFlags("root@tcp(localhost:3306)/", certFile, certFile, keyFile)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root@tcp(localhost:3306)/?tls=custom")
+
+			// Parsing fails for TLS config names that were not registered.
+			cfg, err := mysql.ParseDSN(dsn)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(cfg.TLSConfig, convey.ShouldEqual, "custom")
+		})
+		convey.Convey("Existing dsn parameters are kept", func() {
+			dsn, err := addTLSFlags("root@tcp(localhost:3306)/?parseTime=true", certFile, "", "")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root@tcp(localhost:3306)/?parseTime=true&tls=custom")
+		})
+		convey.Convey("Certificate without key", func() {
+			_, err := addTLSFlags("root@tcp(localhost:3306)/", "", certFile, "")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.ssl-cert and --mysql.ssl-key must be specified together"))
+		})
+		convey.Convey("Missing CA file", func() {
+			_, err := addTLSFlags("root@tcp(localhost:3306)/", filepath.Join(dir, "missing.pem"), "", "")
+			convey.So(err, convey.ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLoadWebConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mysqld_exporter-web-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	writeConfig := func(content string) string {
+		path := filepath.Join(dir, "web-config.json")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	convey.Convey("Web config file", t, func() {
+		convey.Convey("Loads the server certificate", func() {
+			tlsCfg, _, err := loadWebConfig(writeConfig(fmt.Sprintf(
+				`{"tls_server_config": {"cert_file": %q, "key_file": %q}}`, certFile, keyFile)))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(tlsCfg.Certificates, convey.ShouldHaveLength, 1)
+			convey.So(tlsCfg.ClientAuth, convey.ShouldEqual, tls.NoClientCert)
+		})
+		convey.Convey("Loads the client CA", func() {
+			tlsCfg, _, err := loadWebConfig(writeConfig(fmt.Sprintf(
+				`{"tls_server_config": {"cert_file": %q, "key_file": %q, "client_auth_type": "RequireAndVerifyClientCert", "client_ca_file": %q}}`,
+				certFile, keyFile, certFile)))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(tlsCfg.ClientAuth, convey.ShouldEqual, tls.RequireAndVerifyClientCert)
+			convey.So(tlsCfg.ClientCAs, convey.ShouldNotBeNil)
+		})
+		convey.Convey("Requires the certificate", func() {
+			path := writeConfig(`{"tls_server_config": {}}`)
+			_, _, err := loadWebConfig(path)
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("cert_file and key_file of web config %s are required", path))
+		})
+		convey.Convey("Rejects unknown client auth types", func() {
+			path := writeConfig(fmt.Sprintf(
+				`{"tls_server_config": {"cert_file": %q, "key_file": %q, "client_auth_type": "Always"}}`, certFile, keyFile))
+			_, _, err := loadWebConfig(path)
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("invalid client_auth_type \"Always\" in web config %s", path))
+		})
+		convey.Convey("Loads the basic auth users", func() {
+			tlsCfg, users, err := loadWebConfig(writeConfig(`{"basic_auth_users": {"prometheus": "$2y$10$j8dcNrGcG0kdU6fqIoFQxODVR2rzaF5QckctFM9fRU2O.Z8O/pAay"}}`))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(tlsCfg, convey.ShouldBeNil)
+			convey.So(users, convey.ShouldContainKey, "prometheus")
+		})
+		convey.Convey("Rejects passwords that are not bcrypt hashes", func() {
+			path := writeConfig(`{"basic_auth_users": {"prometheus": "secret"}}`)
+			_, _, err := loadWebConfig(path)
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("invalid password of user \"prometheus\" in web config %s: crypto/bcrypt: hashedSecret too short to be a bcrypted password", path))
+		})
+		convey.Convey("Rejects unknown keys", func() {
+			path := writeConfig(`{"tls_config": {}}`)
+			_, _, err := loadWebConfig(path)
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("failed to parse web config %s: yaml: unmarshal errors:\n  line 1: field tls_config not found in type main.webConfig", path))
+		})
+	})
+}
+
+func TestServeTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mysqld_exporter-web-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+	path := filepath.Join(dir, "web-config.json")
+	config := fmt.Sprintf(`{"tls_server_config": {"cert_file": %q, "key_file": %q}}`, certFile, keyFile)
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tlsCfg, _, err := loadWebConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mysql_up 1\n"))
+	})
+	go serve(listener, mux, tlsCfg)
+	defer listener.Close()
+
+	pemCert, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(pemCert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "localhost"}}}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	convey.Convey("Metrics are served over TLS only", t, func() {
+		resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/metrics", port))
+		convey.So(err, convey.ShouldBeNil)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(resp.StatusCode, convey.ShouldEqual, http.StatusOK)
+		convey.So(string(body), convey.ShouldEqual, "mysql_up 1\n")
+
+		resp, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", port))
+		convey.So(err, convey.ShouldBeNil)
+		resp.Body.Close()
+		convey.So(resp.StatusCode, convey.ShouldEqual, http.StatusBadRequest)
+	})
+}
+
+func TestAddSocketFlag(t *testing.T) {
+	convey.Convey("Socket flag", t, func() {
+		convey.Convey("No socket leaves the dsn untouched", func() {
+			dsn, err := addSocketFlag("root@tcp(db.example.com:3306)/", "")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root@tcp(db.example.com:3306)/")
+		})
+		convey.Convey("Socket replaces the local TCP address", func() {
+			dsn, err := addSocketFlag("root:abc@tcp(localhost:3306)/", "/var/run/mysqld/mysqld.sock")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root:abc@unix(/var/run/mysqld/mysqld.sock)/")
+		})
+		convey.Convey("Socket is used when the dsn has no address", func() {
+			dsn, err := addSocketFlag("root:abc@/?parseTime=true", "/tmp/mysql.sock")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root:abc@unix(/tmp/mysql.sock)/?parseTime=true")
+		})
+		convey.Convey("Socket conflicts with a remote host", func() {
+			_, err := addSocketFlag("root:abc@tcp(db.example.com:3306)/", "/tmp/mysql.sock")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.socket conflicts with host db.example.com:3306 in the mysql dsn"))
+		})
+	})
+}
+
+func TestAddCharsetFlag(t *testing.T) {
+	convey.Convey("Charset flag", t, func() {
+		convey.Convey("No charset leaves the dsn untouched", func() {
+			dsn := addCharsetFlag("root@tcp(db.example.com:3306)/", "")
+			convey.So(dsn, convey.ShouldEqual, "root@tcp(db.example.com:3306)/")
+		})
+		convey.Convey("Charset and collation are added to the dsn", func() {
+			dsn := addCharsetFlag("root:abc@tcp(localhost:3306)/?parseTime=true", "utf8mb4")
+			cfg, err := mysql.ParseDSN(dsn)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(cfg.Params["charset"], convey.ShouldEqual, "utf8mb4")
+			convey.So(cfg.Collation, convey.ShouldEqual, "utf8mb4_general_ci")
+			convey.So(cfg.ParseTime, convey.ShouldBeTrue)
+		})
+		convey.Convey("A charset in the dsn is kept", func() {
+			dsn := addCharsetFlag("root@/?charset=latin1", "utf8mb4")
+			convey.So(dsn, convey.ShouldEqual, "root@/?charset=latin1")
+		})
+		convey.Convey("An invalid dsn is kept", func() {
+			dsn := addCharsetFlag("127.0.0.1:3306", "utf8mb4")
+			convey.So(dsn, convey.ShouldEqual, "127.0.0.1:3306")
+		})
+		convey.Convey("A collation in the dsn is kept", func() {
+			dsn := addCharsetFlag("root@/?collation=utf8mb4_bin", "utf8mb4")
+			convey.So(dsn, convey.ShouldEqual, "root@/?collation=utf8mb4_bin")
+		})
+	})
+}
+
+func TestAddCloudSQLFlag(t *testing.T) {
+	convey.Convey("Cloud SQL instance flag", t, func() {
+		convey.Convey("No instance keeps the dsn", func() {
+			dsn, err := addCloudSQLFlag("root@tcp(db.example.com:3306)/", "", "", "/cloudsql")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root@tcp(db.example.com:3306)/")
+		})
+		convey.Convey("Instance replaces the local TCP address", func() {
+			dsn, err := addCloudSQLFlag("root:abc@tcp(localhost:3306)/?parseTime=true", "project:region:instance", "", "/cloudsql")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "root:abc@cloudsql-proxy(project:region:instance)/?parseTime=true")
+		})
+		convey.Convey("Instance conflicts with the socket flag", func() {
+			_, err := addCloudSQLFlag("root:abc@/", "project:region:instance", "/tmp/mysql.sock", "/cloudsql")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.cloud-sql-instance conflicts with --mysql.socket"))
+		})
+		convey.Convey("Instance conflicts with a socket in the dsn", func() {
+			_, err := addCloudSQLFlag("root:abc@unix(/tmp/mysql.sock)/", "project:region:instance", "", "/cloudsql")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.cloud-sql-instance conflicts with socket /tmp/mysql.sock in the mysql dsn"))
+		})
+		convey.Convey("Instance conflicts with a remote host", func() {
+			_, err := addCloudSQLFlag("root:abc@tcp(db.example.com:3306)/", "project:region:instance", "", "/cloudsql")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.cloud-sql-instance conflicts with host db.example.com:3306 in the mysql dsn"))
+		})
+	})
+}
+
+func TestCloudSQLDialer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cloudsql-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	listener, err := net.Listen("unix", filepath.Join(dir, "project:region:instance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	dsn, err := addCloudSQLFlag("root@/", "project:region:instance", "", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Net != cloudSQLNetwork {
+		t.Fatalf("dsn %q uses the %s network, want %s", dsn, cfg.Net, cloudSQLNetwork)
+	}
+
+	// The dialer is handed the instance connection name from the dsn.
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+		accepted <- err
+	}()
+	conn, err := cloudSQLDialer(dir)(cfg.Addr)
+	if err != nil {
+		t.Fatalf("the cloudsql dialer did not connect to the instance socket: %s", err)
+	}
+	conn.Close()
+	if err := <-accepted; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddRDSIAMAuth(t *testing.T) {
+	convey.Convey("RDS IAM authentication", t, func() {
+		convey.Convey("Cleartext passwords are allowed over TLS", func() {
+			dsn, err := addRDSIAMAuth("exporter@tcp(db.example.com:3306)/?tls=true")
+			convey.So(err, convey.ShouldBeNil)
+			cfg, err := mysql.ParseDSN(dsn)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(cfg.AllowCleartextPasswords, convey.ShouldBeTrue)
+		})
+		convey.Convey("TLS is required", func() {
+			_, err := addRDSIAMAuth("exporter@tcp(db.example.com:3306)/")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.auth-mode=rds-iam needs TLS, set tls in the dsn or --mysql.ssl-ca"))
+		})
+		convey.Convey("Sockets are rejected", func() {
+			_, err := addRDSIAMAuth("exporter@unix(/tmp/mysql.sock)/?tls=true")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.auth-mode=rds-iam needs a tcp dsn, got \"unix\""))
+		})
+	})
+}
+
+func TestRDSAuthToken(t *testing.T) {
+	newSession := func(region string) *session.Session {
+		sess, err := session.NewSession(&aws.Config{
+			Region:      aws.String(region),
+			Credentials: credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", "session token"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sess
+	}
+
+	convey.Convey("RDS IAM authentication tokens", t, func() {
+		convey.Convey("Token is a presigned connect URL", func() {
+			provider, err := newRDSIAMProvider("exporter@tcp(db.example.com:3306)/?tls=true", newSession("eu-west-1"))
+			convey.So(err, convey.ShouldBeNil)
+			token, err := provider.GetPassword(context.Background())
+			convey.So(err, convey.ShouldBeNil)
+			u, err := url.Parse("https://" + token)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(u.Host, convey.ShouldEqual, "db.example.com:3306")
+			convey.So(token, convey.ShouldStartWith, "db.example.com:3306?")
+			q := u.Query()
+			convey.So(q.Get("Action"), convey.ShouldEqual, "connect")
+			convey.So(q.Get("DBUser"), convey.ShouldEqual, "exporter")
+			convey.So(q.Get("X-Amz-Credential"), convey.ShouldStartWith, "AKIDEXAMPLE/")
+			convey.So(q.Get("X-Amz-Credential"), convey.ShouldEndWith, "/eu-west-1/rds-db/aws4_request")
+			convey.So(q.Get("X-Amz-Expires"), convey.ShouldEqual, "900")
+			convey.So(q.Get("X-Amz-Security-Token"), convey.ShouldEqual, "session token")
+			convey.So(q.Get("X-Amz-Signature"), convey.ShouldHaveLength, 64)
+		})
+		convey.Convey("Region of the RDS endpoint", func() {
+			provider, err := newRDSIAMProvider("exporter@tcp(mydb.c9akciq32.us-east-2.rds.amazonaws.com:3306)/?tls=true", newSession(""))
+			convey.So(err, convey.ShouldBeNil)
+			token, err := provider.GetPassword(context.Background())
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(token, convey.ShouldContainSubstring, "%2Fus-east-2%2Frds-db%2Faws4_request")
+		})
+		convey.Convey("Region is required", func() {
+			_, err := newRDSIAMProvider("exporter@tcp(db.example.com:3306)/?tls=true", newSession(""))
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("--mysql.auth-mode=rds-iam needs AWS_REGION to be set for endpoint db.example.com:3306"))
+		})
+	})
+}
+
+func TestRDSEndpointRegion(t *testing.T) {
+	convey.Convey("Region of RDS endpoints", t, func() {
+		convey.So(rdsEndpointRegion("mydb.c9akciq32.eu-west-1.rds.amazonaws.com:3306"), convey.ShouldEqual, "eu-west-1")
+		convey.So(rdsEndpointRegion("mydb.cluster-c9akciq32.us-east-2.rds.amazonaws.com"), convey.ShouldEqual, "us-east-2")
+		convey.So(rdsEndpointRegion("mydb.c9akciq32.cn-north-1.rds.amazonaws.com.cn:3306"), convey.ShouldEqual, "cn-north-1")
+		convey.So(rdsEndpointRegion("db.example.com:3306"), convey.ShouldEqual, "")
+		convey.So(rdsEndpointRegion("rds.amazonaws.com:3306"), convey.ShouldEqual, "")
+	})
+}
+
+func TestServeMetricsCredentialProvider(t *testing.T) {
+	defer func() { newCredentialProvider = nil }()
+	newCredentialProvider = func(dsn string) (collector.CredentialProvider, error) {
+		return nil, errors.New("no credentials for " + dsn)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	serveMetrics(rec, req, "user@tcp(127.0.0.1:1)/", collector.NewMetrics(), nil, prometheus.Gatherers{})
+	convey.Convey("Scrapes fail without credentials", t, func() {
+		convey.So(rec.Code, convey.ShouldEqual, http.StatusInternalServerError)
+		convey.So(rec.Body.String(), convey.ShouldContainSubstring, "no credentials for user@tcp(127.0.0.1:1)/")
+	})
+}
+
+// flakyPinger fails the given number of pings before succeeding.
+type flakyPinger struct {
+	failures int
+	pings    int
+}
+
+func (p *flakyPinger) PingContext(ctx context.Context) error {
+	p.pings++
+	if p.pings <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestPingWithRetry(t *testing.T) {
+	convey.Convey("Startup connection retries", t, func() {
+		convey.Convey("Succeeds once MySQL is up", func() {
+			db := &flakyPinger{failures: 2}
+			err := pingWithRetry(context.Background(), db, 3, time.Second, time.Millisecond)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(db.pings, convey.ShouldEqual, 3)
+		})
+		convey.Convey("Gives up after the retries", func() {
+			db := &flakyPinger{failures: 5}
+			err := pingWithRetry(context.Background(), db, 2, time.Second, time.Millisecond)
+			convey.So(err, convey.ShouldBeError, "connection refused")
+			convey.So(db.pings, convey.ShouldEqual, 3)
+		})
+		convey.Convey("Stops when cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			db := &flakyPinger{failures: 5}
+			err := pingWithRetry(ctx, db, 10, time.Second, time.Hour)
+			convey.So(err, convey.ShouldEqual, context.Canceled)
+			convey.So(db.pings, convey.ShouldEqual, 1)
+		})
+	})
+}
+
+func TestNewHandlerStartup(t *testing.T) {
+	kingpin.CommandLine.Parse([]string{"--metrics.namespace=mydb"})
+	defer kingpin.CommandLine.Parse([]string{})
+	metrics := collector.NewMetrics()
+	metrics.MySQLUp.Set(1)
+	registry := collector.NewScraperRegistry(map[collector.Scraper]bool{collector.ScrapeGlobalStatus{}: true})
+	handler := newHandler(metrics, registry, make(chan struct{}))
+
+	convey.Convey("MySQL is reported down until the startup retries are over", t, func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		convey.So(w.Code, convey.ShouldEqual, http.StatusOK)
+		body := w.Body.String()
+		convey.So(body, convey.ShouldContainSubstring, "\nmydb_up 0\n")
+		convey.So(body, convey.ShouldContainSubstring, "\ngo_goroutines ")
+		convey.So(body, convey.ShouldNotContainSubstring, "mydb_exporter_")
+		convey.So(body, convey.ShouldNotContainSubstring, "mysql_")
+	})
+}
+
+func TestNamespaceGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "mysql", Name: "up", Help: "Whether the MySQL server is up."}),
+		prometheus.NewGauge(prometheus.GaugeOpts{Namespace: "other", Name: "up", Help: "Unrelated metric."}),
+	)
+
+	convey.Convey("Metric families are renamed to the namespace", t, func() {
+		mfs, err := namespaceGatherer{Gatherer: registry, namespace: "mydb"}.Gather()
+		convey.So(err, convey.ShouldBeNil)
+		var names []string
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		convey.So(names, convey.ShouldResemble, []string{"mydb_up", "other_up"})
+	})
+}
+
+func TestParseConstLabels(t *testing.T) {
+	convey.Convey("Const labels flag", t, func() {
+		convey.Convey("Parses label pairs", func() {
+			labels, err := parseConstLabels(" role=primary, cluster=eu-1,empty=")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(labels, convey.ShouldResemble, prometheus.Labels{"role": "primary", "cluster": "eu-1", "empty": ""})
+		})
+		convey.Convey("Accepts an empty list", func() {
+			labels, err := parseConstLabels("")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(labels, convey.ShouldBeEmpty)
+		})
+		convey.Convey("Rejects pairs without value", func() {
+			_, err := parseConstLabels("role")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("invalid const label \"role\", expected label=value"))
+		})
+		convey.Convey("Rejects invalid label names", func() {
+			_, err := parseConstLabels("eu-1=cluster")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("invalid const label name \"eu-1\""))
+			_, err = parseConstLabels("__name__=up")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("invalid const label name \"__name__\""))
+		})
+		convey.Convey("Rejects duplicate labels", func() {
+			_, err := parseConstLabels("role=primary,role=replica")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("duplicate const label \"role\""))
+		})
+	})
+}
+
+func TestServeMetricsConstLabels(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer func(old prometheus.Labels) { constLabels = old }(constLabels)
+	constLabels = prometheus.Labels{"role": "primary", "cluster": "eu-1"}
+
+	// Nothing listens on port 1, MySQL is reported as down.
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	serveMetrics(rec, req, "user:pass@tcp(127.0.0.1:1)/", collector.NewMetrics(), nil, prometheus.Gatherers{})
+
+	convey.Convey("Const labels are added to the MySQL metrics", t, func() {
+		convey.So(rec.Code, convey.ShouldEqual, http.StatusOK)
+		convey.So(rec.Body.String(), convey.ShouldContainSubstring, "\nmysql_up{cluster=\"eu-1\",role=\"primary\"} 0\n")
+		convey.So(rec.Body.String(), convey.ShouldContainSubstring, "\nmysql_exporter_scrapes_total{cluster=\"eu-1\",role=\"primary\"} 1\n")
+	})
+}
+
+func TestExcludeGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	for _, name := range []string{"mysql_sys_user_table_scans_total", "mysql_sys_user_statements_total", "mysql_up"} {
+		registry.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: "Test metric."}))
+	}
+	excluded, err := parseExcludedMetrics("mysql_sys_user_table_scans_total, mysql_missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("Excluded metric families are dropped", t, func() {
+		mfs, err := excludeGatherer{Gatherer: registry, excluded: excluded}.Gather()
+		convey.So(err, convey.ShouldBeNil)
+		var names []string
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		convey.So(names, convey.ShouldResemble, []string{"mysql_sys_user_statements_total", "mysql_up"})
+	})
+	convey.Convey("Names are matched after the namespace rename", t, func() {
+		excluded, err := parseExcludedMetrics("custom_up")
+		convey.So(err, convey.ShouldBeNil)
+		mfs, err := excludeGatherer{Gatherer: namespaceGatherer{Gatherer: registry, namespace: "custom"}, excluded: excluded}.Gather()
+		convey.So(err, convey.ShouldBeNil)
+		var names []string
+		for _, mf := range mfs {
+			names = append(names, mf.GetName())
+		}
+		convey.So(names, convey.ShouldResemble, []string{"custom_sys_user_statements_total", "custom_sys_user_table_scans_total"})
+	})
+	convey.Convey("Invalid metric names are rejected", t, func() {
+		_, err := parseExcludedMetrics("mysql_up,mysql-up")
+		convey.So(err, convey.ShouldBeError, fmt.Errorf("invalid metric name \"mysql-up\" in --metrics.exclude"))
+	})
+}
+
+func TestServeMetricsExclude(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer func(old map[string]bool) { excludedMetrics = old }(excludedMetrics)
+	excludedMetrics = map[string]bool{"mysql_exporter_scrapes_total": true}
+
+	// Nothing listens on port 1, MySQL is reported as down.
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	serveMetrics(rec, req, "user:pass@tcp(127.0.0.1:1)/", collector.NewMetrics(), nil, prometheus.Gatherers{})
+
+	convey.Convey("Excluded metrics are not served", t, func() {
+		convey.So(rec.Code, convey.ShouldEqual, http.StatusOK)
+		convey.So(rec.Body.String(), convey.ShouldNotContainSubstring, "mysql_exporter_scrapes_total")
+		convey.So(rec.Body.String(), convey.ShouldContainSubstring, "\nmysql_up 0\n")
+		convey.So(rec.Body.String(), convey.ShouldContainSubstring, "mysql_exporter_last_scrape_error")
+	})
+}
+
+func TestCollectorsHandler(t *testing.T) {
+	registry := collector.NewScraperRegistry(map[collector.Scraper]bool{
+		collector.ScrapeGlobalStatus{}: true,
+		collector.ScrapeBinlogSize{}:   false,
+	})
+	handler := newCollectorsHandler(registry)
+
+	request := func(method string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/collectors", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	convey.Convey("Collectors endpoint", t, func() {
+		convey.Convey("Lists collectors", func() {
+			rec := request(http.MethodGet, nil)
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusOK)
+			convey.So(rec.Body.String(), convey.ShouldEqual, `{"binlog_size":false,"global_status":true}`+"\n")
+		})
+		convey.Convey("Toggles collectors", func() {
+			rec := request(http.MethodPost, url.Values{"enable": {"binlog_size"}, "disable": {"global_status"}})
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusOK)
+			convey.So(rec.Body.String(), convey.ShouldEqual, `{"binlog_size":true,"global_status":false}`+"\n")
+			convey.So(registry.Enabled(), convey.ShouldResemble, []collector.Scraper{collector.ScrapeBinlogSize{}})
+		})
+		convey.Convey("Rejects unknown collectors", func() {
+			rec := request(http.MethodPost, url.Values{"enable": {"nope"}})
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusBadRequest)
+		})
+		convey.Convey("Rejects other methods", func() {
+			rec := request(http.MethodDelete, nil)
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusMethodNotAllowed)
+		})
+	})
+}
+
+func TestProbeHandler(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer func(old string) { dsn = old }(dsn)
+	dsn = "user:pass@tcp(localhost:3306)/"
+
+	registry := collector.NewScraperRegistry(map[collector.Scraper]bool{
+		collector.ScrapeGlobalStatus{}: true,
+	})
+	// Nothing listens on port 1, the probe reports the target as down.
+	handler := newProbeHandler(registry, []string{"127.0.0.1:1", "db1"})
+
+	request := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/probe?target="+url.QueryEscape(target), nil)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	convey.Convey("Probe endpoint", t, func() {
+		convey.Convey("Scrapes allowed targets", func() {
+			rec := request("127.0.0.1:1")
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusOK)
+			convey.So(rec.Body.String(), convey.ShouldContainSubstring, "\nmysql_up 0\n")
+			convey.So(rec.Body.String(), convey.ShouldContainSubstring, "mysql_exporter_scrapes_total 1\n")
+			convey.So(rec.Body.String(), convey.ShouldNotContainSubstring, "go_goroutines")
+		})
+		convey.Convey("Does not share exporter metrics between requests", func() {
+			request("127.0.0.1:1")
+			rec := request("127.0.0.1:1")
+			convey.So(rec.Body.String(), convey.ShouldContainSubstring, "mysql_exporter_scrapes_total 1\n")
+		})
+		convey.Convey("Rejects other targets", func() {
+			rec := request("127.0.0.1:3306")
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusForbidden)
+		})
+		convey.Convey("Requires a target", func() {
+			rec := request("")
+			convey.So(rec.Code, convey.ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func TestProbeDSN(t *testing.T) {
+	convey.Convey("Probe dsn", t, func() {
+		convey.Convey("Replaces the address", func() {
+			got, err := probeDSN("user:pass@tcp(localhost:3306)/?tls=skip-verify", probeTargetAddr("db1"))
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldEqual, "user:pass@tcp(db1:3306)/?tls=skip-verify")
+		})
+		convey.Convey("Replaces sockets", func() {
+			got, err := probeDSN("user:pass@unix(/tmp/mysql.sock)/", "db1:3307")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldEqual, "user:pass@tcp(db1:3307)/")
+		})
+	})
+}
+
 // bin stores information about path of executable and attached port
 type bin struct {
 	path string
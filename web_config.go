@@ -0,0 +1,151 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v2"
+)
+
+// webConfig is the part of the exporter-toolkit web configuration file
+// supported by the exporter. Unknown keys are rejected, like the toolkit
+// does.
+type webConfig struct {
+	TLSServerConfig *webTLSServerConfig `yaml:"tls_server_config"`
+	BasicAuthUsers  map[string]string   `yaml:"basic_auth_users"`
+}
+
+// webTLSServerConfig is the tls_server_config of a web configuration file.
+type webTLSServerConfig struct {
+	CertFile       string `yaml:"cert_file"`
+	KeyFile        string `yaml:"key_file"`
+	ClientAuthType string `yaml:"client_auth_type"`
+	ClientCAFile   string `yaml:"client_ca_file"`
+}
+
+// webMaxBcryptCost bounds the cost of the basic_auth_users hashes, as each
+// unauthenticated request with a known user runs a bcrypt verification.
+const webMaxBcryptCost = 14
+
+// webClientAuthTypes maps the client_auth_type values to their tls setting.
+var webClientAuthTypes = map[string]tls.ClientAuthType{
+	"":                           tls.NoClientCert,
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+// loadWebConfig returns the TLS configuration of the web server, nil
+// without tls_server_config, and the bcrypt hashes of the basic_auth_users
+// from the --web.config.file file.
+func loadWebConfig(path string) (*tls.Config, map[string]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cfg webConfig
+	if err := yaml.UnmarshalStrict(content, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse web config %s: %s", path, err)
+	}
+	for user, hash := range cfg.BasicAuthUsers {
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid password of user %q in web config %s: %s", user, path, err)
+		}
+		if cost > webMaxBcryptCost {
+			return nil, nil, fmt.Errorf("bcrypt cost %d of user %q in web config %s is above %d", cost, user, path, webMaxBcryptCost)
+		}
+	}
+	tlsServerConfig := cfg.TLSServerConfig
+	if tlsServerConfig == nil {
+		return nil, cfg.BasicAuthUsers, nil
+	}
+	if tlsServerConfig.CertFile == "" || tlsServerConfig.KeyFile == "" {
+		return nil, nil, fmt.Errorf("cert_file and key_file of web config %s are required", path)
+	}
+	keypair, err := tls.LoadX509KeyPair(tlsServerConfig.CertFile, tlsServerConfig.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse pem-encoded cert %s or key %s: %s",
+			tlsServerConfig.CertFile, tlsServerConfig.KeyFile, err)
+	}
+	clientAuth, ok := webClientAuthTypes[tlsServerConfig.ClientAuthType]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid client_auth_type %q in web config %s", tlsServerConfig.ClientAuthType, path)
+	}
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{keypair},
+		ClientAuth:   clientAuth,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if tlsServerConfig.ClientCAFile != "" {
+		pemCA, err := ioutil.ReadFile(tlsServerConfig.ClientCAFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsCfg.ClientCAs = x509.NewCertPool()
+		if !tlsCfg.ClientCAs.AppendCertsFromPEM(pemCA) {
+			return nil, nil, fmt.Errorf("failed parse pem-encoded CA certificates from %s", tlsServerConfig.ClientCAFile)
+		}
+	}
+	return tlsCfg, cfg.BasicAuthUsers, nil
+}
+
+// basicAuth requires one of users to authenticate the requests of handler.
+// The password last verified for each user is cached, as each bcrypt
+// verification takes as long as the cost of the hash asks for. Verifications
+// run one at a time, so that failed logins cannot take all CPUs.
+func basicAuth(handler http.Handler, users map[string]string) http.Handler {
+	var (
+		mu       sync.Mutex
+		verified = map[string][sha256.Size]byte{}
+		bcryptMu sync.Mutex
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		hash, known := users[user]
+		if ok = ok && known; ok {
+			sum := sha256.Sum256([]byte(password))
+			mu.Lock()
+			last, cached := verified[user]
+			mu.Unlock()
+			if !cached || subtle.ConstantTimeCompare(last[:], sum[:]) != 1 {
+				bcryptMu.Lock()
+				ok = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+				bcryptMu.Unlock()
+				if ok {
+					mu.Lock()
+					verified[user] = sum
+					mu.Unlock()
+				}
+			}
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mysqld_exporter"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
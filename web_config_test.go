@@ -0,0 +1,162 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testPasswordHash is the bcrypt hash of "secret".
+const testPasswordHash = "$2y$10$j8dcNrGcG0kdU6fqIoFQxODVR2rzaF5QckctFM9fRU2O.Z8O/pAay"
+
+func TestLoadWebConfigYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mysqld_exporter-web-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCertificate(t, dir)
+	path := filepath.Join(dir, "web-config.yml")
+
+	convey.Convey("Web config file in YAML", t, func() {
+		convey.Convey("Loads the exporter-toolkit format", func() {
+			config := fmt.Sprintf(`---
+# TLS and basic auth of the exporter.
+tls_server_config:
+  cert_file: %s
+  key_file: '%s'  # unencrypted
+  client_auth_type: "RequireAndVerifyClientCert"
+  client_ca_file: %s
+
+basic_auth_users:
+  prometheus: %s
+  "grafana": '%s'
+`, certFile, keyFile, certFile, testPasswordHash, testPasswordHash)
+			convey.So(ioutil.WriteFile(path, []byte(config), 0600), convey.ShouldBeNil)
+			tlsCfg, users, err := loadWebConfig(path)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(tlsCfg.Certificates, convey.ShouldHaveLength, 1)
+			convey.So(tlsCfg.ClientAuth, convey.ShouldEqual, tls.RequireAndVerifyClientCert)
+			convey.So(tlsCfg.ClientCAs, convey.ShouldNotBeNil)
+			convey.So(users, convey.ShouldHaveLength, 2)
+			convey.So(users["grafana"], convey.ShouldEqual, testPasswordHash)
+		})
+		convey.Convey("Serves plain HTTP without tls_server_config", func() {
+			config := "basic_auth_users:\n  prometheus: " + testPasswordHash + "\n"
+			convey.So(ioutil.WriteFile(path, []byte(config), 0600), convey.ShouldBeNil)
+			tlsCfg, users, err := loadWebConfig(path)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(tlsCfg, convey.ShouldBeNil)
+			convey.So(users, convey.ShouldContainKey, "prometheus")
+		})
+	})
+}
+
+func TestParseWebConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mysqld_exporter-web-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "web-config.yml")
+	load := func(config string) (map[string]string, error) {
+		if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, users, err := loadWebConfig(path)
+		return users, err
+	}
+
+	convey.Convey("YAML of web configs", t, func() {
+		convey.Convey("Flow style and anchors", func() {
+			users, err := load("basic_auth_users: {prometheus: &hash '" + testPasswordHash + "', grafana: *hash}\n")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(users, convey.ShouldResemble, map[string]string{"prometheus": testPasswordHash, "grafana": testPasswordHash})
+		})
+		convey.Convey("Empty files", func() {
+			users, err := load("# nothing\n")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(users, convey.ShouldBeEmpty)
+		})
+		convey.Convey("Unknown keys", func() {
+			_, err := load("tls_server_config:\n  cert: server.crt\n")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("failed to parse web config %s: yaml: unmarshal errors:\n  line 2: field cert not found in type main.webTLSServerConfig", path))
+		})
+		convey.Convey("Expensive hashes", func() {
+			hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+			convey.So(err, convey.ShouldBeNil)
+			hash = []byte(strings.Replace(string(hash), "$04$", "$20$", 1))
+			_, err = load("basic_auth_users:\n  prometheus: " + string(hash) + "\n")
+			convey.So(err, convey.ShouldBeError, fmt.Errorf("bcrypt cost 20 of user \"prometheus\" in web config %s is above 14", path))
+		})
+	})
+}
+
+func TestBasicAuth(t *testing.T) {
+	hash := func(password string) string {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(hash)
+	}
+	handler := basicAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("mysql_up 1\n"))
+	}), map[string]string{"prometheus": testPasswordHash, "a": hash("b:c"), "grafana": hash("other")})
+
+	get := func(user, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	convey.Convey("Basic auth", t, func() {
+		convey.Convey("Users are let in", func() {
+			for i := 0; i < 2; i++ {
+				w := get("prometheus", "secret")
+				convey.So(w.Code, convey.ShouldEqual, http.StatusOK)
+				convey.So(w.Body.String(), convey.ShouldEqual, "mysql_up 1\n")
+			}
+			convey.So(get("a", "b:c").Code, convey.ShouldEqual, http.StatusOK)
+		})
+		convey.Convey("Others are not", func() {
+			for _, credentials := range [][2]string{{"", ""}, {"prometheus", "wrong"}, {"nobody", "secret"}} {
+				w := get(credentials[0], credentials[1])
+				convey.So(w.Code, convey.ShouldEqual, http.StatusUnauthorized)
+				convey.So(w.Header().Get("WWW-Authenticate"), convey.ShouldEqual, `Basic realm="mysqld_exporter"`)
+			}
+		})
+		convey.Convey("Cached passwords are not shared between users", func() {
+			convey.So(get("prometheus", "secret").Code, convey.ShouldEqual, http.StatusOK)
+			convey.So(get("grafana", "secret").Code, convey.ShouldEqual, http.StatusUnauthorized)
+			convey.So(get("a", "b:c").Code, convey.ShouldEqual, http.StatusOK)
+			convey.So(get("prometheus", "b:c").Code, convey.ShouldEqual, http.StatusUnauthorized)
+		})
+	})
+}